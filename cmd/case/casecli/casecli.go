@@ -1,9 +1,6 @@
 package casecli
 
 import (
-	"fmt"
-	"io"
-	"os"
 	"strings"
 
 	"github.com/khinshankhan/yui/lib/caseconv"
@@ -13,7 +10,12 @@ import (
 func NewCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Text case conversion tools").
 		WithAliases(aliases...).
-		WithArgs(cli.VariadicArg("conversion"), cli.RequiredArg("text")).
+		AsPure().
+		WithArgs(cli.RequiredArg("conversion"), cli.VariadicArg("text").FromStdin()).
+		RegisterFlags(
+			cli.StringFlag("style", "", "style", "Title case style used by the title conversion").WithDefault(string(caseconv.StyleAPA)),
+		).
+		WithRecords().
 		WithSections(
 			cli.Section{
 				Title: "CONVERSIONS",
@@ -24,6 +26,7 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 					"snake      Convert to snake_case",
 					"camel      Convert to camelCase",
 					"pascal     Convert to PascalCase",
+					"title      Convert to Title Case in the --style style",
 				},
 			},
 			cli.Section{
@@ -42,47 +45,131 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 			cli.Section{
 				Title: "CHAINING",
 				Lines: []string{
-					"Chain multiple conversions by using multiple conversion tokens.",
+					"Chain multiple conversions by separating them with commas.",
 					"Conversions are applied left-to-right.",
+					"Words that name conversions at the start of the text chain too, as in",
+					"%cmd% snake upper \"Hello World\"; quote text that starts with one.",
 				},
 			},
 		).
-		WithExamples(
-			"%cmd% lower \"Hello World\"             # hello world",
-			"%cmd% kebab \"Hello World\"             # hello-world",
-			"%cmd% snake upper \"Hello World\"       # HELLO_WORLD",
-			"echo \"Hello World\" | %cmd% kebab      # hello-world",
+		WithExampleSpecs(
+			cli.Example{Args: []string{"lower", `"Hello World"`}, Output: "hello world"},
+			cli.Example{Args: []string{"kebab", `"Hello World"`}, Output: "hello-world"},
+			cli.Example{Args: []string{"snake,upper", `"Hello World"`}, Output: "HELLO_WORLD"},
+			cli.Example{Args: []string{"snake", "upper", `"Hello World"`}, Output: "HELLO_WORLD"},
+			cli.Example{Args: []string{"title", `"war and peace"`}, Output: "War and Peace"},
+			cli.Example{Args: []string{"kebab"}, Stdin: "Hello World", Output: "hello-world"},
+			cli.Example{Args: []string{"title", "--each-line", "<", "titles.txt"}, Note: "Title-case every line of a file", External: true},
 		).
+		WithComplete(complete).
 		WithRun(run)
 }
 
+func complete(ctx *cli.Context, args []string, toComplete string) ([]string, cli.CompDirective) {
+	if len(args) > 0 {
+		return nil, cli.CompleteNoFiles
+	}
+	chain := toComplete[:strings.LastIndex(toComplete, ",")+1]
+	var candidates []string
+	for _, mode := range append(caseconv.Modes(), "title") {
+		candidates = append(candidates, chain+mode)
+	}
+	return candidates, cli.CompleteNoFiles
+}
+
 func run(ctx *cli.Context, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("at least one argument is required")
+	chained, words := chainedWords(ctx.Variadic("text"), ctx.Piped())
+	modes, err := conversionModes(ctx, append(strings.Split(ctx.Arg("conversion"), ","), chained...))
+	if err != nil {
+		return err
 	}
 
-	var (
-		input string
-		modes []string
-	)
+	if ctx.Records() {
+		if len(chained) > 0 {
+			return cli.Errorf(cli.KindUsage, "with --each-line or --null, chain conversions with commas: %s", strings.Join(append([]string{ctx.Arg("conversion")}, chained...), ","))
+		}
+		return ctx.EachRecord("text", func(record string) (string, error) {
+			return convert(record, modes).Output, nil
+		})
+	}
 
-	stat, err := os.Stdin.Stat()
-	if err == nil && (stat.Mode()&os.ModeCharDevice) == 0 {
-		b, readErr := io.ReadAll(os.Stdin)
-		if readErr != nil {
-			return fmt.Errorf("read stdin: %w", readErr)
+	var input string
+	switch {
+	case len(chained) == 0:
+		input, err = ctx.TrimmedInput("text")
+	case len(words) == 0:
+		input, err = ctx.ReadStdin()
+		input = strings.TrimSpace(input)
+		if err == nil && input == "" {
+			err = cli.Errorf(cli.KindUsage, "text required via argument or stdin")
 		}
-		input = strings.TrimSpace(string(b))
-		modes = args
-	} else {
-		input = args[len(args)-1]
-		modes = args[:len(args)-1]
+	default:
+		input = strings.Join(words, " ")
 	}
+	if err != nil {
+		return err
+	}
+	return ctx.Render(convert(input, modes))
+}
+
+// chainedWords splits the leading words of the text that name conversions
+// off the rest, keeping the space-separated chain, snake upper "Hello World",
+// working. The last word is always text unless stdin is piped.
+func chainedWords(words []string, piped bool) (chained, rest []string) {
+	for len(words) > 0 && (len(words) > 1 || piped) && (words[0] == "title" || caseconv.IsMode(words[0])) {
+		chained = append(chained, words[0])
+		words = words[1:]
+	}
+	return chained, words
+}
 
+// conversionModes resolves the conversion chain to caseconv modes, applying
+// --style to title.
+func conversionModes(ctx *cli.Context, modes []string) ([]string, error) {
+	for i, mode := range modes {
+		if mode == "title" {
+			style := ctx.String("style")
+			if !caseconv.IsMode("title-" + style) {
+				return nil, cli.Errorf(cli.KindUsage, "unknown title style: %s%s", style, cli.DidYouMean(style, titleStyles()))
+			}
+			modes[i] = "title-" + style
+			continue
+		}
+		if !caseconv.IsMode(mode) {
+			return nil, cli.Errorf(cli.KindUsage, "unknown conversion: %s%s", mode, cli.DidYouMean(mode, append(caseconv.Modes(), "title")))
+		}
+	}
+	return modes, nil
+}
+
+func convert(input string, modes []string) caseResult {
+	result := caseResult{Input: input, Output: input, Steps: []caseStep{}}
 	for _, mode := range modes {
-		input = caseconv.Convert(input, mode)
+		result.Output = caseconv.Convert(result.Output, mode)
+		result.Steps = append(result.Steps, caseStep{Mode: mode, Output: result.Output})
 	}
+	return result
+}
+
+type caseStep struct {
+	Mode   string `json:"mode"`
+	Output string `json:"output"`
+}
+
+type caseResult struct {
+	Input  string     `json:"input"`
+	Steps  []caseStep `json:"steps"`
+	Output string     `json:"output"`
+}
 
-	fmt.Fprintln(ctx.Stdout, input)
-	return nil
+func (r caseResult) Text() string {
+	return r.Output
+}
+
+func titleStyles() []string {
+	var styles []string
+	for _, style := range caseconv.AvailableTitleStyles() {
+		styles = append(styles, string(style))
+	}
+	return styles
 }
@@ -0,0 +1,35 @@
+package casecli_test
+
+import (
+	"testing"
+
+	"github.com/khinshankhan/yui/cmd/case/casecli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func TestCase(t *testing.T) {
+	clitest.RunGolden(t, casecli.NewCommand("case"),
+		clitest.Case{Name: "lower", Args: []string{"lower", "Hello World"}},
+		clitest.Case{Name: "kebab", Args: []string{"kebab", "Hello World"}},
+		clitest.Case{Name: "chain", Args: []string{"snake,upper", "Hello World"}},
+		clitest.Case{Name: "chain-words", Args: []string{"snake", "upper", "Hello World"}},
+		clitest.Case{Name: "chain-words-stdin", Args: []string{"snake", "upper"}, Stdin: "Hello World\n"},
+		clitest.Case{Name: "chain-words-records", Args: []string{"snake", "upper", "-l"}, Stdin: "Hello World\n"},
+		clitest.Case{Name: "mode-word-as-text", Args: []string{"kebab", "upper"}},
+		clitest.Case{Name: "title", Args: []string{"title", "war and peace"}},
+		clitest.Case{Name: "title-style", Args: []string{"title", "--style", "chicago", "a tale of two cities"}},
+		clitest.Case{Name: "stdin", Args: []string{"kebab"}, Stdin: "Hello World\n"},
+		clitest.Case{Name: "stdin-and-argument", Args: []string{"kebab", "Hello World"}, Stdin: "x\n"},
+		clitest.Case{Name: "unquoted-words", Args: []string{"kebab", "Hello", "World"}},
+		clitest.Case{Name: "unknown-conversion", Args: []string{"kebap", "Hello World"}},
+		clitest.Case{Name: "unknown-style", Args: []string{"title", "--style", "chicgo", "x"}},
+		clitest.Case{Name: "each-line", Args: []string{"kebab", "--each-line"}, Stdin: "Hello World\n\n  Good Night Moon\nlast line"},
+		clitest.Case{Name: "each-line-pairs", Args: []string{"upper", "-l", "--pairs"}, Stdin: "a b\nc d\n"},
+		clitest.Case{Name: "null", Args: []string{"snake", "-z"}, Stdin: "Hello World\x00Two\nLines\x00"},
+		clitest.Case{Name: "pairs-without-records", Args: []string{"upper", "--pairs", "x"}},
+	)
+}
+
+func TestExamples(t *testing.T) {
+	clitest.RunExamples(t, casecli.NewCommand("case"))
+}
@@ -1,9 +1,9 @@
 package clipcli
 
 import (
-	"fmt"
+	"errors"
 	"io"
-	"os"
+	"slices"
 	"strings"
 
 	"github.com/khinshankhan/yui/lib/cli"
@@ -14,9 +14,11 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Clipboard tools").
 		WithAliases(aliases...).
 		WithSubcommandName("action").
-		WithExample("Copy stdin into the clipboard", "copy").
-		WithExample("Copy an argument into the clipboard", "copy", "\"hello world\"").
-		WithExample("Paste clipboard contents", "paste").
+		WithExampleSpecs(
+			cli.Example{Args: []string{"copy"}, Note: "Copy stdin into the clipboard", External: true},
+			cli.Example{Args: []string{"copy", `"hello world"`}, Note: "Copy an argument into the clipboard", External: true},
+			cli.Example{Args: []string{"paste"}, Note: "Paste clipboard contents", External: true},
+		).
 		Register(
 			NewCopyCommand("copy"),
 			NewPasteCommand("paste"),
@@ -26,56 +28,68 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 func NewCopyCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Copy text into the clipboard").
 		WithAliases(aliases...).
-		WithArgs(cli.OptionalArg("text")).
+		WithArgs(cli.VariadicArg("text").FromStdin()).
+		RegisterFlags(backendFlag()).
 		WithRun(runCopy)
 }
 
 func NewPasteCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Paste text from the clipboard").
 		WithAliases(aliases...).
+		RegisterFlags(backendFlag()).
 		WithRun(runPaste)
 }
 
+func backendFlag() cli.Flag {
+	return cli.StringFlag("backend", "b", "name", "Clipboard tool to use when installed: "+strings.Join(clipboard.Backends(), ", "))
+}
+
+// backend returns the --backend value after checking it names a backend
+// this platform knows.
+func backend(ctx *cli.Context) (string, error) {
+	name := ctx.String("backend")
+	if name != "" && !slices.Contains(clipboard.Backends(), name) {
+		return "", cli.Errorf(cli.KindUsage, "unknown clipboard backend: %s%s", name, cli.DidYouMean(name, clipboard.Backends()))
+	}
+	return name, nil
+}
+
 func runCopy(ctx *cli.Context, args []string) error {
-	input, err := readClipboardInput(args)
+	input, err := ctx.Input("text")
 	if err != nil {
 		return err
 	}
 
-	if err := clipboard.Copy(input); err != nil {
+	name, err := backend(ctx)
+	if err != nil {
 		return err
 	}
 
+	if err := clipboard.CopyPreferring(ctx.Context(), name, input); err != nil {
+		return classify(err)
+	}
+
 	return nil
 }
 
 func runPaste(ctx *cli.Context, args []string) error {
-	if len(args) > 0 {
-		return fmt.Errorf("paste does not accept arguments")
+	name, err := backend(ctx)
+	if err != nil {
+		return err
 	}
 
-	text, err := clipboard.Paste()
+	text, err := clipboard.PastePreferring(ctx.Context(), name)
 	if err != nil {
-		return err
+		return classify(err)
 	}
 
 	_, err = io.WriteString(ctx.Stdout, text)
 	return err
 }
 
-func readClipboardInput(args []string) (string, error) {
-	stat, err := os.Stdin.Stat()
-	if err == nil && (stat.Mode()&os.ModeCharDevice) == 0 {
-		b, readErr := io.ReadAll(os.Stdin)
-		if readErr != nil {
-			return "", fmt.Errorf("read stdin: %w", readErr)
-		}
-		return string(b), nil
+func classify(err error) error {
+	if errors.Is(err, clipboard.ErrNoBackend) {
+		return cli.NewError(cli.KindUnavailable, err)
 	}
-
-	if len(args) == 0 {
-		return "", fmt.Errorf("text required via argument or stdin")
-	}
-
-	return strings.Join(args, " "), nil
+	return err
 }
@@ -0,0 +1,26 @@
+package clipcli_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khinshankhan/yui/cmd/clip/clipcli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+	"github.com/khinshankhan/yui/lib/clipboard"
+)
+
+func TestClip(t *testing.T) {
+	board := &clipboard.Memory{}
+	ctx := clipboard.WithClipboard(context.Background(), board)
+
+	clitest.RunGolden(t, clipcli.NewCommand("clip"),
+		clitest.Case{Name: "copy-arg", Args: []string{"copy", "hello world"}, Context: ctx},
+		clitest.Case{Name: "paste-arg", Args: []string{"paste"}, Context: ctx},
+		clitest.Case{Name: "copy-stdin", Args: []string{"copy"}, Stdin: "from stdin\n", Context: ctx},
+		clitest.Case{Name: "paste-stdin", Args: []string{"paste"}, Context: ctx},
+	)
+}
+
+func TestExamples(t *testing.T) {
+	clitest.RunExamples(t, clipcli.NewCommand("clip"))
+}
@@ -1,22 +1,26 @@
 package colorcli
 
 import (
-	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/khinshankhan/yui/lib/cli"
 	"github.com/khinshankhan/yui/lib/colorconv"
 )
 
+var targetFormats = []string{"hex", "rgb", "hsl", "hsv", "hsb", "cmyk", "oklch", "oklab", "lab"}
+
 func NewCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Color conversion tools").
 		WithAliases(aliases...).
+		AsPure().
 		WithArgs(
 			cli.
 				OptionalArg("target-format"),
 			cli.
-				RequiredArg("color"),
+				VariadicArg("color").FromStdin(),
 		).
+		WithRecords().
 		WithSections(
 			cli.Section{
 				Title: "TARGET FORMATS",
@@ -45,71 +49,159 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 				},
 			},
 		).
-		WithExamples(
-			"%cmd% help                        # Show help",
-			"%cmd% \"#ff5500\"                   # Show all formats",
-			"%cmd% rgb \"#ff5500\"               # rgb(255, 85, 0)",
-			"%cmd% hsl \"#ff5500\"               # hsl(20.0, 100.0%, 50.0%)",
-			"%cmd% hex \"rgb(255, 85, 0)\"       # #ff5500",
-			"%cmd% oklch \"hsl(20, 100%, 50%)\"  # oklch(0.655 0.203 41.3)",
-			"%cmd% red                         # Show all formats for red",
-			"%cmd% hex \"oklch(0.7 0.15 60)\"    # Convert OKLCH to hex",
+		WithExampleSpecs(
+			cli.Example{Args: []string{"help"}, Note: "Show help"},
+			cli.Example{Args: []string{`"#ff5500"`}, Note: "Show all formats"},
+			cli.Example{Args: []string{"rgb", `"#ff5500"`}, Output: "rgb(255, 85, 0)"},
+			cli.Example{Args: []string{"hsl", `"#ff5500"`}, Output: "hsl(20.0, 100.0%, 50.0%)"},
+			cli.Example{Args: []string{"hex", `"rgb(255, 85, 0)"`}, Output: "#ff5500"},
+			cli.Example{Args: []string{"oklch", `"hsl(20, 100%, 50%)"`}, Output: "oklch(0.676 0.217 38.8)"},
+			cli.Example{Args: []string{"red"}, Note: "Show all formats for red"},
+			cli.Example{Args: []string{"hex", `"oklch(0.7 0.15 60)"`}, Note: "Convert OKLCH to hex", Output: "#e18528"},
+			cli.Example{Args: []string{"hex", "--each-line", "<", "palette.txt"}, Note: "Convert every color in a file to hex", External: true},
 		).
+		WithComplete(complete).
 		WithRun(run)
 }
 
-func run(ctx *cli.Context, args []string) error {
+func complete(ctx *cli.Context, args []string, toComplete string) ([]string, cli.CompDirective) {
 	if len(args) == 0 {
-		return fmt.Errorf("color value required")
+		return append(append([]string{}, targetFormats...), colorconv.NamedColorNames()...), cli.CompleteNoFiles
 	}
+	return colorconv.NamedColorNames(), cli.CompleteNoFiles
+}
 
-	subCmd := strings.ToLower(args[0])
-	targetFormat := ""
-	colorInput := ""
+func run(ctx *cli.Context, args []string) error {
+	targetFormat := strings.ToLower(ctx.Arg("target-format"))
+	if ctx.Records() {
+		return runRecords(ctx, targetFormat)
+	}
 
-	switch subCmd {
-	case "hex", "rgb", "hsl", "cmyk", "oklch", "oklab", "lab":
-		targetFormat = subCmd
-		colorInput = strings.Join(args[1:], " ")
-	case "hsv", "hsb":
-		targetFormat = "hsv"
-		colorInput = strings.Join(args[1:], " ")
-	default:
-		colorInput = strings.Join(args, " ")
+	// With stdin not a terminal, a lone word binds to target-format and the
+	// color is read from stdin; a word that is not a format is the color.
+	if len(ctx.Variadic("color")) == 0 && targetFormat != "" && targetFormat != "hsb" && !slices.Contains(targetFormats, targetFormat) {
+		return convert(ctx, ctx.Arg("target-format"), "")
+	}
+
+	colorInput, err := ctx.TrimmedInput("color")
+	if err != nil {
+		return err
 	}
 
-	if colorInput == "" {
-		return fmt.Errorf("color value required")
+	switch {
+	case targetFormat == "hsb":
+		targetFormat = "hsv"
+	case targetFormat == "" || slices.Contains(targetFormats, targetFormat):
+	default:
+		// Not a format: the first word of an unquoted color such as rgb(255, 85, 0).
+		colorInput = strings.TrimSpace(ctx.Arg("target-format") + " " + colorInput)
+		targetFormat = ""
 	}
+	return convert(ctx, colorInput, targetFormat)
+}
 
+// convert renders colorInput in targetFormat, or in every format when
+// targetFormat is empty.
+func convert(ctx *cli.Context, colorInput, targetFormat string) error {
 	color, err := colorconv.Parse(colorInput)
 	if err != nil {
-		return err
+		if targetFormat == "" && ctx.Arg("target-format") != "" {
+			// A mistyped format reads as part of the color; point at the format.
+			if suggestion := cli.DidYouMean(ctx.Arg("target-format"), targetFormats); suggestion != "" {
+				return cli.Errorf(cli.KindUsage, "unknown target format: %s%s", ctx.Arg("target-format"), suggestion)
+			}
+		}
+		return cli.NewError(cli.KindUsage, err)
 	}
 
 	if targetFormat == "" {
-		fmt.Fprintln(ctx.Stdout, color.FormatAll())
-		return nil
+		return ctx.Render(allFormats(color))
 	}
 
+	return ctx.Render(conversionResult{Format: targetFormat, Value: format(color, targetFormat)})
+}
+
+// runRecords converts each record of the color input to targetFormat. The
+// format is required, since every format at once does not fit on a line.
+func runRecords(ctx *cli.Context, targetFormat string) error {
+	if targetFormat == "hsb" {
+		targetFormat = "hsv"
+	}
+	if !slices.Contains(targetFormats, targetFormat) {
+		if targetFormat == "" {
+			return cli.Errorf(cli.KindUsage, "--each-line and --null need a target format")
+		}
+		return cli.Errorf(cli.KindUsage, "unknown target format: %s%s", ctx.Arg("target-format"), cli.DidYouMean(ctx.Arg("target-format"), targetFormats))
+	}
+
+	return ctx.EachRecord("color", func(record string) (string, error) {
+		color, err := colorconv.Parse(record)
+		if err != nil {
+			return "", err
+		}
+		return format(color, targetFormat), nil
+	})
+}
+
+func format(color colorconv.Color, targetFormat string) string {
 	switch targetFormat {
 	case "hex":
-		fmt.Fprintln(ctx.Stdout, color.Hex())
+		return color.Hex()
 	case "rgb":
-		fmt.Fprintln(ctx.Stdout, color.FormatRGB())
+		return color.FormatRGB()
 	case "hsl":
-		fmt.Fprintln(ctx.Stdout, color.FormatHSL())
+		return color.FormatHSL()
 	case "hsv":
-		fmt.Fprintln(ctx.Stdout, color.FormatHSV())
+		return color.FormatHSV()
 	case "cmyk":
-		fmt.Fprintln(ctx.Stdout, color.FormatCMYK())
+		return color.FormatCMYK()
 	case "oklch":
-		fmt.Fprintln(ctx.Stdout, color.FormatOKLCH())
+		return color.FormatOKLCH()
 	case "oklab":
-		fmt.Fprintln(ctx.Stdout, color.FormatOKLab())
+		return color.FormatOKLab()
 	case "lab":
-		fmt.Fprintln(ctx.Stdout, color.FormatLab())
+		return color.FormatLab()
+	default:
+		return ""
 	}
+}
+
+type conversionResult struct {
+	Format string `json:"format"`
+	Value  string `json:"value"`
+}
+
+func (r conversionResult) Text() string {
+	return r.Value
+}
+
+type formatsResult struct {
+	Hex   string `json:"hex"`
+	RGB   string `json:"rgb"`
+	HSL   string `json:"hsl"`
+	HSV   string `json:"hsv"`
+	CMYK  string `json:"cmyk"`
+	Lab   string `json:"lab"`
+	OKLab string `json:"oklab"`
+	OKLCH string `json:"oklch"`
+
+	color colorconv.Color
+}
+
+func allFormats(color colorconv.Color) formatsResult {
+	return formatsResult{
+		Hex:   color.Hex(),
+		RGB:   color.FormatRGB(),
+		HSL:   color.FormatHSL(),
+		HSV:   color.FormatHSV(),
+		CMYK:  color.FormatCMYK(),
+		Lab:   color.FormatLab(),
+		OKLab: color.FormatOKLab(),
+		OKLCH: color.FormatOKLCH(),
+		color: color,
+	}
+}
 
-	return nil
+func (r formatsResult) Text() string {
+	return r.color.FormatAll()
 }
@@ -0,0 +1,32 @@
+package colorcli_test
+
+import (
+	"testing"
+
+	"github.com/khinshankhan/yui/cmd/color/colorcli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func TestColor(t *testing.T) {
+	clitest.RunGolden(t, colorcli.NewCommand("color"),
+		clitest.Case{Name: "all-formats", Args: []string{"#ff5500"}},
+		clitest.Case{Name: "rgb", Args: []string{"rgb", "#ff5500"}},
+		clitest.Case{Name: "hsl", Args: []string{"hsl", "#ff5500"}},
+		clitest.Case{Name: "hex", Args: []string{"hex", "rgb(255, 85, 0)"}},
+		clitest.Case{Name: "oklch", Args: []string{"oklch", "hsl(20, 100%, 50%)"}},
+		clitest.Case{Name: "named", Args: []string{"red"}},
+		clitest.Case{Name: "from-oklch", Args: []string{"hex", "oklch(0.7 0.15 60)"}},
+		clitest.Case{Name: "invalid", Args: []string{"hex", "not-a-color"}},
+		clitest.Case{Name: "stdin", Args: []string{"rgb"}, Stdin: "#ff5500\n"},
+		clitest.Case{Name: "color-empty-stdin", Args: []string{"red"}, Stdin: "\n"},
+		clitest.Case{Name: "color-and-stdin", Args: []string{"red"}, Stdin: "blue\n"},
+		clitest.Case{Name: "typo-empty-stdin", Args: []string{"rbg"}, Stdin: "\n"},
+		clitest.Case{Name: "stdin-and-argument", Args: []string{"rgb", "#ff5500"}, Stdin: "blue\n"},
+		clitest.Case{Name: "each-line", Args: []string{"hex", "--each-line", "--pairs"}, Stdin: "red\nnot-a-color\nrgb(0, 0, 255)\n"},
+		clitest.Case{Name: "each-line-no-format", Args: []string{"--each-line"}, Stdin: "red\n"},
+	)
+}
+
+func TestExamples(t *testing.T) {
+	clitest.RunExamples(t, colorcli.NewCommand("color"))
+}
@@ -7,7 +7,17 @@ import (
 	"github.com/khinshankhan/yui/lib/cli"
 )
 
+// Set at build time with -ldflags "-X main.Version=... -X main.CommitHash=... -X main.BuildDate=...".
+var (
+	Version    = "dev"
+	CommitHash string
+	BuildDate  string
+)
+
 func main() {
-	root := netcli.NewCommand("net")
+	root := netcli.NewCommand("net").
+		WithSections(cli.ExitCodeSection()).
+		RegisterPersistentFlags(cli.OutputFlag()).
+		WithVersion(cli.NewBuildInfo(Version, CommitHash, BuildDate))
 	os.Exit(cli.Execute(root, os.Args[1:], os.Stdout, os.Stderr))
 }
@@ -1,7 +1,9 @@
 package netcli
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/khinshankhan/yui/lib/cli"
 	"github.com/khinshankhan/yui/lib/nettools"
@@ -11,8 +13,10 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 	ip := cli.New("ip", "Get local IP addresses").
 		WithAliases("i").
 		WithSubcommandName("type").
-		WithExample("Get primary local IP").
-		WithExample("Get all interfaces and IPs", "all").
+		WithExampleSpecs(
+			cli.Example{Note: "Get primary local IP", External: true},
+			cli.Example{Args: []string{"all"}, Note: "Get all interfaces and IPs", External: true},
+		).
 		WithDefaultSubcommand("primary").
 		Register(
 			cli.
@@ -28,46 +32,56 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Network/IP tools").
 		WithAliases(aliases...).
 		WithExample("Show help", "help").
-		WithExample("Get primary local IP", "ip").
-		WithExample("Get all IPs", "ip", "all").
+		WithExampleSpecs(
+			cli.Example{Args: []string{"ip"}, Note: "Get primary local IP", External: true},
+			cli.Example{Args: []string{"ip", "all"}, Note: "Get all IPs", External: true},
+		).
 		Register(ip)
 }
 
 func runPrimary(ctx *cli.Context, args []string) error {
-	if len(args) > 0 {
-		return fmt.Errorf("unknown %s subcommand: %s", ctx.Command.Name, args[0])
+	ip, err := nettools.GetPrimaryLocalIPContext(ctx.Context())
+	if errors.Is(err, nettools.ErrNoAddress) {
+		return cli.NewError(cli.KindUnavailable, err)
 	}
-
-	ip, err := nettools.GetPrimaryLocalIP()
 	if err != nil {
 		return err
 	}
-	fmt.Fprintln(ctx.Stdout, ip)
-	return nil
+	return ctx.Render(primaryResult{Address: ip})
 }
 
 func runAll(ctx *cli.Context, args []string) error {
-	if len(args) > 0 {
-		return fmt.Errorf("unknown %s subcommand: %s", ctx.Command.Name, args[0])
-	}
-
 	interfaces, err := nettools.GetLocalIPs()
 	if err != nil {
 		return err
 	}
+	return ctx.Render(allResult(interfaces))
+}
+
+type primaryResult struct {
+	Address string `json:"address"`
+}
+
+func (r primaryResult) Text() string {
+	return r.Address
+}
+
+type allResult []nettools.NetworkInterface
 
-	for _, iface := range interfaces {
-		fmt.Fprintf(ctx.Stdout, "%s:\n", iface.Name)
+func (r allResult) Text() string {
+	var b strings.Builder
+	for _, iface := range r {
+		fmt.Fprintf(&b, "%s:\n", iface.Name)
 		if iface.MacAddress != "" {
-			fmt.Fprintf(ctx.Stdout, "  MAC: %s\n", iface.MacAddress)
+			fmt.Fprintf(&b, "  MAC: %s\n", iface.MacAddress)
 		}
 		for _, ip := range iface.IPs {
 			ipType := "IPv4"
 			if ip.IsIPv6 {
 				ipType = "IPv6"
 			}
-			fmt.Fprintf(ctx.Stdout, "  %s: %s\n", ipType, ip.Address)
+			fmt.Fprintf(&b, "  %s: %s\n", ipType, ip.Address)
 		}
 	}
-	return nil
+	return strings.TrimSuffix(b.String(), "\n")
 }
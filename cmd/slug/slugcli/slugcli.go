@@ -1,11 +1,6 @@
 package slugcli
 
 import (
-	"fmt"
-	"io"
-	"os"
-	"strings"
-
 	"github.com/khinshankhan/yui/lib/cli"
 	"github.com/khinshankhan/yui/lib/slug"
 )
@@ -13,93 +8,55 @@ import (
 func NewCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Slug generation tools").
 		WithAliases(aliases...).
-		WithArgs(cli.OptionalArg("text")).
+		AsPure().
+		WithArgs(cli.VariadicArg("text").FromStdin()).
 		RegisterFlags(
-			cli.Flag{
-				Name:        "reserved",
-				Short:       "r",
-				Value:       "slug",
-				Description: "Reserve a slug value; repeat to allocate the next available match",
-			},
+			cli.StringFlag("reserved", "r", "slug", "Reserve a slug value; repeat to allocate the next available match").Repeated(),
 		).
-		WithExamples(
-			"%cmd% \"Some Title\"                                # some-title",
-			"%cmd% \"Some Title\" --reserved some-title          # some-title-1",
-			"%cmd% \"Some Title\" -r some-title -r some-title-1  # some-title-2",
-			"echo \"Some Title\" | %cmd%                         # some-title",
+		WithRecords().
+		WithExampleSpecs(
+			cli.Example{Args: []string{`"Some Title"`}, Output: "some-title"},
+			cli.Example{Args: []string{`"Some Title"`, "--reserved", "some-title"}, Output: "some-title-1"},
+			cli.Example{Args: []string{`"Some Title"`, "-r", "some-title", "-r", "some-title-1"}, Output: "some-title-2"},
+			cli.Example{Stdin: "Some Title", Output: "some-title"},
+			cli.Example{Args: []string{"--each-line", "--pairs", "<", "headings.txt"}, Note: "Print each heading with its slug", External: true},
 		).
 		WithRun(run)
 }
 
 func run(ctx *cli.Context, args []string) error {
-	text, reserved, err := parseArgs(args)
-	if err != nil {
-		return err
+	reserved := ctx.Strings("reserved")
+	if ctx.Records() {
+		// Each slug reserves itself, so the slugs of a document's headings
+		// stay unique.
+		return ctx.EachRecord("text", func(record string) (string, error) {
+			s := makeSlug(record, reserved)
+			reserved = append(reserved, s)
+			return s, nil
+		})
 	}
 
-	if text == "" {
-		text, err = readStdin()
-		if err != nil {
-			return err
-		}
-	}
-
-	if strings.TrimSpace(text) == "" {
-		return fmt.Errorf("text required via argument or stdin")
+	text, err := ctx.TrimmedInput("text")
+	if err != nil {
+		return err
 	}
+	return ctx.Render(slugResult{Input: text, Slug: makeSlug(text, reserved), Reserved: reserved})
+}
 
+func makeSlug(text string, reserved []string) string {
 	base := slug.Make(text)
-	result := base
 	if len(reserved) > 0 {
-		result = slug.NextAvailable(base, reserved)
+		return slug.NextAvailable(base, reserved)
 	}
-
-	fmt.Fprintln(ctx.Stdout, result)
-	return nil
+	return base
 }
 
-func parseArgs(args []string) (string, []string, error) {
-	var (
-		textParts []string
-		reserved  []string
-	)
-
-	for i := 0; i < len(args); i++ {
-		switch args[i] {
-		case "--reserved", "-r":
-			if i+1 >= len(args) {
-				return "", nil, fmt.Errorf("%s requires a value", args[i])
-			}
-			reserved = append(reserved, args[i+1])
-			i++
-		default:
-			if strings.HasPrefix(args[i], "--reserved=") {
-				value := strings.TrimPrefix(args[i], "--reserved=")
-				if value == "" {
-					return "", nil, fmt.Errorf("--reserved requires a value")
-				}
-				reserved = append(reserved, value)
-				continue
-			}
-			if strings.HasPrefix(args[i], "-") {
-				return "", nil, fmt.Errorf("unknown flag: %s", args[i])
-			}
-			textParts = append(textParts, args[i])
-		}
-	}
-
-	return strings.Join(textParts, " "), reserved, nil
+type slugResult struct {
+	Input    string   `json:"input"`
+	Slug     string   `json:"slug"`
+	Reserved []string `json:"reserved,omitempty"`
 }
 
-func readStdin() (string, error) {
-	stat, err := os.Stdin.Stat()
-	if err == nil && (stat.Mode()&os.ModeCharDevice) == 0 {
-		b, readErr := io.ReadAll(os.Stdin)
-		if readErr != nil {
-			return "", fmt.Errorf("read stdin: %w", readErr)
-		}
-		return strings.TrimSpace(string(b)), nil
-	}
-
-	return "", nil
+func (r slugResult) Text() string {
+	return r.Slug
 }
@@ -0,0 +1,24 @@
+package slugcli_test
+
+import (
+	"testing"
+
+	"github.com/khinshankhan/yui/cmd/slug/slugcli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func TestSlug(t *testing.T) {
+	clitest.RunGolden(t, slugcli.NewCommand("slug"),
+		clitest.Case{Name: "title", Args: []string{"Some Title"}},
+		clitest.Case{Name: "reserved", Args: []string{"Some Title", "--reserved", "some-title"}},
+		clitest.Case{Name: "reserved-twice", Args: []string{"Some Title", "-r", "some-title", "-r", "some-title-1"}},
+		clitest.Case{Name: "stdin", Stdin: "Some Title\n"},
+		clitest.Case{Name: "stdin-and-argument", Args: []string{"Some Title"}, Stdin: "x\n"},
+		clitest.Case{Name: "no-text"},
+		clitest.Case{Name: "each-line", Args: []string{"-l", "-r", "intro"}, Stdin: "Intro\nSome Title\n\nIntro\n"},
+	)
+}
+
+func TestExamples(t *testing.T) {
+	clitest.RunExamples(t, slugcli.NewCommand("slug"))
+}
@@ -1,7 +1,8 @@
 package soundcli
 
 import (
-	"fmt"
+	"errors"
+	"io/fs"
 	"os"
 	"runtime"
 
@@ -13,8 +14,10 @@ func NewCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Play sound files").
 		WithAliases(aliases...).
 		WithSubcommandName("action").
-		WithExample("Play the default notification sound", "ping").
-		WithExample("Play a specific file", "play", "alert.wav").
+		WithExampleSpecs(
+			cli.Example{Args: []string{"ping"}, Note: "Play the default notification sound", External: true},
+			cli.Example{Args: []string{"play", "alert.wav"}, Note: "Play a specific file", External: true},
+		).
 		WithDefaultSubcommand("ping").
 		Register(
 			NewPingCommand("ping"),
@@ -32,27 +35,41 @@ func NewPlayCommand(name string, aliases ...string) *cli.Command {
 	return cli.New(name, "Play a specific sound file").
 		WithAliases(aliases...).
 		WithArgs(cli.RequiredArg("file")).
+		WithComplete(completeFile).
+		AsUnserved().
 		WithRun(runPlay)
 }
 
 func runPing(ctx *cli.Context, args []string) error {
-	if len(args) > 0 {
-		return fmt.Errorf("ping does not accept arguments")
-	}
-
 	file := defaultSound()
 	if file == "" {
-		return fmt.Errorf("no default notification sound found for this platform")
+		return cli.Errorf(cli.KindUnavailable, "no default notification sound found for this platform")
 	}
 
-	return sound.Play(file)
+	return classify(sound.PlayContext(ctx.Context(), file))
 }
 
 func runPlay(ctx *cli.Context, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("file argument required")
+	file := ctx.Arg("file")
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		return cli.Errorf(cli.KindNotFound, "sound file not found: %s", file)
+	}
+
+	return classify(sound.PlayContext(ctx.Context(), file))
+}
+
+func classify(err error) error {
+	if errors.Is(err, sound.ErrNoPlayer) {
+		return cli.NewError(cli.KindUnavailable, err)
+	}
+	return err
+}
+
+func completeFile(ctx *cli.Context, args []string, toComplete string) ([]string, cli.CompDirective) {
+	if len(args) > 0 {
+		return nil, cli.CompleteNoFiles
 	}
-	return sound.Play(args[0])
+	return nil, cli.CompleteFiles
 }
 
 func defaultSound() string {
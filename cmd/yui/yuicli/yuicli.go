@@ -0,0 +1,79 @@
+package yuicli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/khinshankhan/yui/cmd/case/casecli"
+	"github.com/khinshankhan/yui/cmd/clip/clipcli"
+	"github.com/khinshankhan/yui/cmd/color/colorcli"
+	"github.com/khinshankhan/yui/cmd/net/netcli"
+	"github.com/khinshankhan/yui/cmd/slug/slugcli"
+	"github.com/khinshankhan/yui/cmd/sound/soundcli"
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func NewCommand(name string) *cli.Command {
+	return cli.New(name, "A collection of micro tools").
+		WithSubcommandName("command").
+		WithPrefixMatching().
+		WithMulticall().
+		WithPlugins().
+		WithSections(cli.ExitCodeSection()).
+		RegisterPersistentFlags(
+			cli.OutputFlag(),
+			cli.BoolFlag("debug", "", "Log the resolved command and its timing to stderr"),
+		).
+		WithPreRun(debugStart).
+		WithPostRun(debugFinish).
+		WithConfig().
+		Register(
+			casecli.NewCommand("case", "c"),
+			slugcli.NewCommand("slug", "s"),
+			clipcli.NewCommand("clip", "clipboard"),
+			clipcli.NewCopyCommand("copy"),
+			clipcli.NewPasteCommand("paste"),
+			colorcli.NewCommand("color", "col"),
+			netcli.NewCommand("net", "n"),
+			soundcli.NewCommand("sound", "snd"),
+			cli.NewCompletionCommand("completion"),
+			cli.NewDocsCommand("docs"),
+			cli.NewConfigCommand("config"),
+			cli.NewInstallLinksCommand("install-links"),
+			cli.NewShellCommand("shell"),
+			cli.NewPipeCommand("pipe"),
+			cli.NewBatchCommand("batch"),
+			cli.NewServeCommand("serve"),
+		)
+}
+
+type debugStartKey struct{}
+
+// debugStart logs which command the arguments resolved to, after aliases,
+// prefixes and multicall names, and the config files that set its defaults.
+func debugStart(ctx *cli.Context) error {
+	if !ctx.Bool("debug") {
+		return nil
+	}
+	fmt.Fprintf(ctx.Stderr, "debug: running %s\n", strings.Join(ctx.Path, " "))
+	for _, file := range ctx.ConfigFiles() {
+		fmt.Fprintf(ctx.Stderr, "debug: config %s\n", file)
+	}
+	ctx.SetContext(context.WithValue(ctx.Context(), debugStartKey{}, time.Now()))
+	return nil
+}
+
+func debugFinish(ctx *cli.Context, err error) error {
+	start, ok := ctx.Context().Value(debugStartKey{}).(time.Time)
+	if !ok {
+		return err
+	}
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	fmt.Fprintf(ctx.Stderr, "debug: %s finished in %s: %s\n", strings.Join(ctx.Path, " "), time.Since(start).Round(time.Microsecond), status)
+	return err
+}
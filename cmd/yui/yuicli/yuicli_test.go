@@ -0,0 +1,32 @@
+package yuicli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/cmd/yui/yuicli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func TestYui(t *testing.T) {
+	batch := strings.Join([]string{
+		`{"cmd": ["case", "kebab"], "input": "Hello World"}`,
+		`{"cmd": ["slug", "Some Title"]}`,
+		`{"cmd": ["color", "hex", "not-a-color"]}`,
+		`{"cmd": ["c", "title", "--each-line"], "input": "war and peace\nthe old man and the sea\n"}`,
+	}, "\n") + "\n"
+
+	clitest.RunGolden(t, yuicli.NewCommand("yui"),
+		clitest.Case{Name: "alias", Args: []string{"c", "kebab", "Hello World"}},
+		clitest.Case{Name: "prefix", Args: []string{"sl", "Some Title"}},
+		clitest.Case{Name: "json", Args: []string{"-o", "json", "case", "kebab", "Hello World"}},
+		clitest.Case{Name: "tsv", Args: []string{"color", "red", "--output", "tsv"}},
+		clitest.Case{Name: "env", Args: []string{"case", "title", "a tale of two cities"}, Env: map[string]string{"YUI_CASE_STYLE": "chicago"}},
+		clitest.Case{Name: "unknown-command", Args: []string{"csae"}},
+		clitest.Case{Name: "batch", Args: []string{"batch", "-j", "2"}, Stdin: batch},
+	)
+}
+
+func TestExamples(t *testing.T) {
+	clitest.RunExamples(t, yuicli.NewCommand("yui"))
+}
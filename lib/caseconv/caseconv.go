@@ -1,6 +1,7 @@
 package caseconv
 
 import (
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -40,6 +41,28 @@ func capitalize(s string) string {
 	return string(runes)
 }
 
+// Modes returns the canonical name of every conversion Convert understands.
+func Modes() []string {
+	modes := []string{"lower", "upper", "kebab", "snake", "camel", "pascal", "words"}
+	for _, style := range AvailableTitleStyles() {
+		modes = append(modes, string(style))
+	}
+	return modes
+}
+
+// IsMode reports whether Convert understands mode, including the title-
+// prefixed and abbreviated style names.
+func IsMode(mode string) bool {
+	switch mode {
+	case "cmos", "bb", "nyt", "wiki":
+		return true
+	}
+	if style, ok := strings.CutPrefix(mode, "title-"); ok {
+		return slices.Contains(AvailableTitleStyles(), TitleStyle(style))
+	}
+	return slices.Contains(Modes(), mode)
+}
+
 func Convert(input, mode string) string {
 	switch mode {
 	case "upper":
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FromStdin marks the argument as one that may be supplied on a pipe instead
+// of positionally. Positionals still bind to it first; stdin is read only
+// when it is piped and the argument was given none.
+func (a Arg) FromStdin() Arg {
+	a.Stdin = true
+	return a
+}
+
+func (a Arg) required() bool {
+	return !a.Optional
+}
+
+// bindArgs splits positionals across the declared args. Required args take one
+// value each, optional args take one when enough positionals remain for the
+// required args after them, and a variadic arg takes whatever is left over.
+// Args that may come from stdin are optional when it is piped.
+func bindArgs(specs []Arg, args []string, piped bool) (map[string][]string, error) {
+	positional := make([]Arg, len(specs))
+	for i, spec := range specs {
+		if spec.Stdin && piped {
+			spec.Optional = true
+		}
+		positional[i] = spec
+	}
+
+	needed := make([]int, len(positional)+1)
+	for i := len(positional) - 1; i >= 0; i-- {
+		needed[i] = needed[i+1]
+		if positional[i].required() {
+			needed[i]++
+		}
+	}
+
+	if len(args) < needed[0] {
+		// Report the first required arg left empty after filling left to right.
+		filled := 0
+		for _, spec := range positional {
+			if !spec.required() {
+				continue
+			}
+			if filled == len(args) {
+				return nil, missingArgError(spec)
+			}
+			filled++
+		}
+	}
+
+	bound := make(map[string][]string, len(positional))
+	p := 0
+	for i, spec := range positional {
+		left := len(args) - p
+		after := needed[i+1]
+
+		take := 0
+		switch {
+		case spec.Variadic:
+			take = left - after
+		case spec.Optional:
+			if left > after {
+				take = 1
+			}
+		default:
+			take = 1
+		}
+
+		if take > 0 {
+			bound[spec.Name] = args[p : p+take]
+			p += take
+		}
+	}
+
+	if p < len(args) {
+		return nil, fmt.Errorf("unexpected argument: %s", args[p])
+	}
+
+	return bound, nil
+}
+
+func missingArgError(arg Arg) error {
+	if arg.Stdin {
+		return Errorf(KindUsage, "%s required via argument or stdin", arg.Name)
+	}
+	return Errorf(KindUsage, "missing required argument: %s", formatArg(arg))
+}
+
+// isPiped reports whether r carries input for the command. Files count only
+// when they are not a terminal; any other reader was handed in on purpose.
+func isPiped(r io.Reader) bool {
+	switch r := r.(type) {
+	case nil:
+		return false
+	case *os.File:
+		stat, err := r.Stat()
+		return err == nil && (stat.Mode()&os.ModeCharDevice) == 0
+	default:
+		return true
+	}
+}
+
+func (c *Context) findArg(name string) (Arg, bool) {
+	if c.Command == nil {
+		return Arg{}, false
+	}
+	for _, arg := range c.Command.Args {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return Arg{}, false
+}
+
+// Arg returns the named positional argument. Variadic values are joined
+// with single spaces.
+func (c *Context) Arg(name string) string {
+	return strings.Join(c.args[name], " ")
+}
+
+// Variadic returns every positional value bound to the named argument.
+func (c *Context) Variadic(name string) []string {
+	return append([]string(nil), c.args[name]...)
+}
+
+// Piped reports whether the command was given input on stdin.
+func (c *Context) Piped() bool {
+	return c.piped
+}
+
+// ReadStdin reads all of the command's stdin.
+func (c *Context) ReadStdin() (string, error) {
+	if c.Stdin == nil {
+		return "", nil
+	}
+	b, err := io.ReadAll(c.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("read stdin: %w", err)
+	}
+	return string(b), nil
+}
+
+// fromStdin reports whether the named argument takes its value from stdin:
+// it was declared FromStdin, stdin is piped, and no positionals bound to it.
+func (c *Context) fromStdin(name string) bool {
+	arg, ok := c.findArg(name)
+	return ok && arg.Stdin && c.piped && len(c.args[name]) == 0
+}
+
+// Input returns the named argument, reading it from stdin instead when the
+// argument was declared FromStdin, stdin is piped and no positionals were
+// given for it. The value is returned as-is, trailing newline included.
+func (c *Context) Input(name string) (string, error) {
+	if !c.fromStdin(name) {
+		return c.Arg(name), nil
+	}
+	return c.ReadStdin()
+}
+
+// TrimmedInput is Input with surrounding whitespace removed. A required
+// argument that trims to nothing is reported as missing.
+func (c *Context) TrimmedInput(name string) (string, error) {
+	input, err := c.Input(name)
+	if err != nil {
+		return "", err
+	}
+	input = strings.TrimSpace(input)
+
+	if arg, ok := c.findArg(name); ok && arg.required() && input == "" {
+		return "", missingArgError(arg)
+	}
+	return input, nil
+}
@@ -0,0 +1,130 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestExecuteBindsArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []cli.Arg
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "required",
+			specs: []cli.Arg{cli.RequiredArg("file")},
+			args:  []string{"a.wav"},
+			want:  "file=[a.wav]",
+		},
+		{
+			name:    "required missing",
+			specs:   []cli.Arg{cli.RequiredArg("file")},
+			wantErr: "missing required argument: <file>",
+		},
+		{
+			name:    "no args declared",
+			args:    []string{"x"},
+			wantErr: "unexpected argument: x",
+		},
+		{
+			name:    "too many",
+			specs:   []cli.Arg{cli.OptionalArg("text")},
+			args:    []string{"a", "b"},
+			wantErr: "unexpected argument: b",
+		},
+		{
+			name:  "optional before required left empty",
+			specs: []cli.Arg{cli.OptionalArg("format"), cli.RequiredArg("color")},
+			args:  []string{"red"},
+			want:  "format=[] color=[red]",
+		},
+		{
+			name:  "optional before required filled",
+			specs: []cli.Arg{cli.OptionalArg("format"), cli.RequiredArg("color")},
+			args:  []string{"hex", "red"},
+			want:  "format=[hex] color=[red]",
+		},
+		{
+			name:  "variadic before required",
+			specs: []cli.Arg{cli.VariadicArg("conversion"), cli.RequiredArg("text")},
+			args:  []string{"snake", "upper", "Hello World"},
+			want:  "conversion=[snake upper] text=[Hello World]",
+		},
+		{
+			name:    "variadic before required reports trailing arg",
+			specs:   []cli.Arg{cli.VariadicArg("conversion"), cli.RequiredArg("text")},
+			args:    []string{"kebab"},
+			wantErr: "missing required argument: <text>",
+		},
+		{
+			name:    "variadic needs one value",
+			specs:   []cli.Arg{cli.VariadicArg("text")},
+			wantErr: "missing required argument: <text...>",
+		},
+		{
+			name:  "optional variadic may be empty",
+			specs: []cli.Arg{cli.Arg{Name: "text", Optional: true, Variadic: true}},
+			want:  "text=[]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			cmd := cli.New("tool", "Arg test tool").
+				WithArgs(tc.specs...).
+				WithRun(func(ctx *cli.Context, args []string) error {
+					var parts []string
+					for _, spec := range tc.specs {
+						parts = append(parts, fmt.Sprintf("%s=%v", spec.Name, ctx.Variadic(spec.Name)))
+					}
+					got = strings.Join(parts, " ")
+					return nil
+				})
+
+			var stdout, stderr bytes.Buffer
+			code := cli.Execute(cmd, tc.args, &stdout, &stderr)
+
+			if tc.wantErr != "" {
+				if code == 0 {
+					t.Fatalf("Execute() code = 0, want failure")
+				}
+				if !strings.Contains(stderr.String(), tc.wantErr) {
+					t.Fatalf("Execute() stderr = %q, want %q", stderr.String(), tc.wantErr)
+				}
+				return
+			}
+			if code != 0 {
+				t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+			}
+			if got != tc.want {
+				t.Fatalf("Execute() bound %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestContextArgJoinsVariadic(t *testing.T) {
+	var got string
+	cmd := cli.New("tool", "Arg test tool").
+		WithArgs(cli.OptionalArg("format"), cli.VariadicArg("color")).
+		WithRun(func(ctx *cli.Context, args []string) error {
+			got = ctx.Arg("format") + "|" + ctx.Arg("color")
+			return nil
+		})
+
+	var stdout, stderr bytes.Buffer
+	if code := cli.Execute(cmd, []string{"hex", "rgb(255,", "85,", "0)"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+	}
+	if want := "hex|rgb(255, 85, 0)"; got != want {
+		t.Fatalf("Arg() = %q, want %q", got, want)
+	}
+}
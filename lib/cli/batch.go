@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// NewBatchCommand returns a command that runs JSON Lines requests from stdin
+// against the program's command tree, in-process, writing one JSON result
+// per request in the order the requests arrived.
+func NewBatchCommand(name string) *Command {
+	return New(name, "Run JSON Lines requests from stdin").
+		RegisterFlags(IntFlag("jobs", "j", "n", "Run up to n requests at once; 0 runs one per CPU").WithDefault("1")).
+		WithSections(Section{
+			Title: "REQUESTS",
+			Lines: []string{
+				`Each line of stdin is a request: {"cmd": ["case", "kebab"], "input": "Hello World"}.`,
+				"cmd is the command and its arguments, as they would follow the program name.",
+				"input, when present, is piped to the command's stdin.",
+				`Each request gets a line back: {"output": "hello-world", "exit": 0, "error": null}.`,
+				"output is the command's stdout without its final newline; error is the message",
+				"it failed with, or null. Results come back in request order, even with --jobs.",
+				"Blank lines are skipped.",
+			},
+		}).
+		WithExampleSpecs(
+			Example{
+				Stdin:  `{"cmd":["case","kebab"],"input":"Hello World"}`,
+				Output: `{"output":"hello-world","exit":0,"error":null}`,
+			},
+			Example{Args: []string{"-j", "8", "<", "requests.jsonl"}, Note: "Run eight requests at a time", External: true},
+		).
+		WithRun(runBatch).
+		AsUtility()
+}
+
+type batchRequest struct {
+	Cmd   []string `json:"cmd"`
+	Input *string  `json:"input"`
+}
+
+type batchResult struct {
+	Output string  `json:"output"`
+	Exit   int     `json:"exit"`
+	Error  *string `json:"error"`
+}
+
+func runBatch(ctx *Context, args []string) error {
+	jobs := ctx.Int("jobs")
+	if jobs < 0 {
+		return Errorf(KindUsage, "--jobs must be 0 or more, got %d", jobs)
+	}
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	// Results queue in request order; the writer waits on each in turn, so
+	// at most jobs requests run, and finish, ahead of the one being written.
+	pending := make(chan chan batchResult, jobs)
+	slots := make(chan struct{}, jobs)
+	written := make(chan error, 1)
+	requests, failed := 0, 0
+	go func() {
+		enc := json.NewEncoder(ctx.Stdout)
+		var err error
+		for result := range pending {
+			r := <-result
+			if r.Exit != ExitOK {
+				failed++
+			}
+			if err == nil {
+				err = enc.Encode(r)
+			}
+		}
+		written <- err
+	}()
+
+	config := requestConfig(ctx.Root)
+	next := lineReader(ctx.Context(), ctx.Stdin)
+	var readErr error
+	for line := 1; ; line++ {
+		b, err := next()
+		if err != nil && !errors.Is(err, io.EOF) {
+			readErr = err
+			break
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			requests++
+			result := make(chan batchResult, 1)
+			pending <- result
+			slots <- struct{}{}
+			go func(line int, b []byte) {
+				defer func() { <-slots }()
+				result <- runBatchRequest(ctx, config, line, b)
+			}(line, b)
+		}
+		if err != nil {
+			break
+		}
+	}
+	close(pending)
+
+	if err := <-written; err != nil {
+		return err
+	}
+	if readErr != nil {
+		return readErr
+	}
+	if failed > 0 {
+		return Errorf(KindFailure, "%d of %d requests failed", failed, requests)
+	}
+	return nil
+}
+
+// runBatchRequest decodes and runs the request on one line of input.
+func runBatchRequest(ctx *Context, config *runConfig, line int, b []byte) batchResult {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var req batchRequest
+	if err := dec.Decode(&req); err != nil {
+		return batchFailure(ExitUsage, fmt.Sprintf("line %d: %v", line, err))
+	}
+	if len(req.Cmd) == 0 {
+		return batchFailure(ExitUsage, fmt.Sprintf("line %d: cmd required", line))
+	}
+
+	opts := Options{Context: ctx.Context(), Program: ctx.Path[0], config: config}
+	if req.Input != nil {
+		opts.Stdin = strings.NewReader(*req.Input)
+	}
+	var stdout bytes.Buffer
+	opts.Stdout, opts.Stderr = &stdout, io.Discard
+	code, err := execute(ctx.Root, req.Cmd, opts)
+
+	result := batchResult{Output: strings.TrimSuffix(stdout.String(), "\n"), Exit: code}
+	if err != nil {
+		message := err.Error()
+		result.Error = &message
+	}
+	return result
+}
+
+// lineReader returns a function that reads the next line of r, as
+// bufio.Reader.ReadBytes does, but returns an interrupted error as soon as
+// ctx is cancelled rather than waiting for input that may never come.
+func lineReader(ctx context.Context, r io.Reader) func() ([]byte, error) {
+	type read struct {
+		line []byte
+		err  error
+	}
+	reads := make(chan read)
+	go func() {
+		in := bufio.NewReader(r)
+		for {
+			line, err := in.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				err = fmt.Errorf("read stdin: %w", err)
+			}
+			select {
+			case reads <- read{line, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return func() ([]byte, error) {
+		select {
+		case r := <-reads:
+			return r.line, r.err
+		case <-ctx.Done():
+			return nil, NewError(KindInterrupted, ctx.Err())
+		}
+	}
+}
+
+func batchFailure(code int, message string) batchResult {
+	return batchResult{Exit: code, Error: &message}
+}
@@ -0,0 +1,59 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func newBatchRoot() *cli.Command {
+	return cli.New("tool", "Batch test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		Register(
+			cli.New("echo", "Echo words").WithArgs(cli.VariadicArg("words")).WithRun(func(ctx *cli.Context, args []string) error {
+				return ctx.Render(strings.Join(args, " "))
+			}),
+			newUpperCommand("upper"),
+			newFailCommand(),
+			cli.NewBatchCommand("batch"),
+		)
+}
+
+func TestBatch(t *testing.T) {
+	requests := strings.Join([]string{
+		`{"cmd": ["echo", "a", "b"]}`,
+		`{"cmd": ["upper"], "input": "hi\n"}`,
+		``,
+		`{"cmd": ["fail"]}`,
+		`{"cmd": ["uper"]}`,
+		`{"args": ["echo"]}`,
+		`{"cmd": []}`,
+	}, "\n")
+	clitest.RunGolden(t, newBatchRoot(),
+		clitest.Case{Name: "batch-requests", Args: []string{"batch"}, Stdin: requests},
+		clitest.Case{Name: "batch-bad-jobs", Args: []string{"batch", "-j", "-1"}},
+	)
+}
+
+func TestBatchJobsKeepOrder(t *testing.T) {
+	var stdin, want strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&stdin, "{\"cmd\": [\"upper\"], \"input\": \"line %d\"}\n", i)
+		fmt.Fprintf(&want, "{\"output\":\"LINE %d\",\"exit\":0,\"error\":null}\n", i)
+	}
+
+	root := newBatchRoot()
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(root, []string{"batch", "-j", "8"}, cli.Options{Stdin: strings.NewReader(stdin.String()), Stdout: &stdout, Stderr: &stderr})
+	if code != cli.ExitOK || stdout.String() != want.String() {
+		t.Fatalf("batch -j 8 = %d\n%s\nstderr = %s", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestBatchInterrupted(t *testing.T) {
+	checkInterrupted(t, newBatchRoot(), "batch")
+}
@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
 )
 
 type RunFunc func(ctx *Context, args []string) error
@@ -14,12 +19,17 @@ type Flag struct {
 	Short       string
 	Value       string
 	Description string
+	Type        FlagType
+	Default     string
+	Repeatable  bool
+	NoConfig    bool
 }
 
 type Arg struct {
 	Name     string
 	Optional bool
 	Variadic bool
+	Stdin    bool
 }
 
 type Section struct {
@@ -27,9 +37,24 @@ type Section struct {
 	Lines []string
 }
 
+// Example is a documented invocation. Args are shown as written, quotes
+// and all, and run the way a shell would split them. Stdin, when set, is
+// shown as echoed into the command. Output is the expected stdout, without
+// its trailing newline; only examples with one are run, by
+// clitest.RunExamples. External marks examples whose result depends on the
+// machine, such as its clipboard, audio or network, so they are not run
+// even when they declare Output.
 type Example struct {
-	Args []string
-	Note string
+	Args     []string
+	Note     string
+	Stdin    string
+	Output   string
+	External bool
+}
+
+// Argv returns the arguments the example runs with.
+func (e Example) Argv() ([]string, error) {
+	return SplitArgs(strings.Join(e.Args, " "))
 }
 
 type Command struct {
@@ -41,18 +66,52 @@ type Command struct {
 	SubcommandName    string
 	DefaultSubcommand string
 	Flags             []Flag
+	PersistentFlags   []Flag
 	Sections          []Section
 	ExampleSpecs      []Example
 	Examples          []string
 	Subcommands       []*Command
 	Run               RunFunc
+	Complete          CompleteFunc
+	Hidden            bool
+	PrefixMatching    bool
+	Version           *BuildInfo
+	Multicall         bool
+	Utility           bool
+	Pure              bool
+	Unserved          bool
+	Plugins           bool
+	Config            bool
+	Macros            []Macro
+	PreRun            []PreRunFunc
+	PostRun           []PostRunFunc
+
+	parent *Command
 }
 
 type Context struct {
+	Root    *Command
 	Command *Command
 	Path    []string
+	Stdin   io.Reader
 	Stdout  io.Writer
 	Stderr  io.Writer
+
+	flags        *flagSet
+	args         map[string][]string
+	piped        bool
+	context      context.Context
+	configFiles  []string
+	configMacros []Macro
+}
+
+// Context returns the command's context.Context, cancelled when the process
+// is interrupted. Long-running work should pass it along.
+func (c *Context) Context() context.Context {
+	if c.context == nil {
+		return context.Background()
+	}
+	return c.context
 }
 
 func New(name, description string) *Command {
@@ -101,6 +160,11 @@ func (c *Command) WithExample(note string, args ...string) *Command {
 	return c
 }
 
+func (c *Command) WithExampleSpecs(examples ...Example) *Command {
+	c.ExampleSpecs = append(c.ExampleSpecs, examples...)
+	return c
+}
+
 func (c *Command) WithRun(run RunFunc) *Command {
 	c.Run = run
 	return c
@@ -112,6 +176,9 @@ func (c *Command) WithDefaultSubcommand(name string) *Command {
 }
 
 func (c *Command) Register(subcommands ...*Command) *Command {
+	for _, sub := range subcommands {
+		sub.parent = c
+	}
 	c.Subcommands = append(c.Subcommands, subcommands...)
 	return c
 }
@@ -121,6 +188,13 @@ func (c *Command) RegisterFlags(flags ...Flag) *Command {
 	return c
 }
 
+// RegisterPersistentFlags declares flags that apply to this command and every
+// command below it.
+func (c *Command) RegisterPersistentFlags(flags ...Flag) *Command {
+	c.PersistentFlags = append(c.PersistentFlags, flags...)
+	return c
+}
+
 func RequiredArg(name string) Arg {
 	return Arg{Name: name}
 }
@@ -133,138 +207,364 @@ func VariadicArg(name string) Arg {
 	return Arg{Name: name, Variadic: true}
 }
 
-func Validate(root *Command) error {
-	var errs []string
-	validateCommand(root, []string{root.Name}, &errs)
-	if len(errs) == 0 {
-		return nil
-	}
-	return errors.New(strings.Join(errs, "\n"))
+// Options supplies the streams a command runs against. A nil Stdin reads as
+// empty and is never treated as piped; a nil Context never cancels. Program
+// is the name the binary was invoked as, used for multicall dispatch.
+type Options struct {
+	Context context.Context
+	Program string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+
+	config *runConfig // loaded ahead by batch and serve; nil reads it afresh
 }
 
+// Execute runs root against the process's stdin and argv[0], cancelling the
+// command's context on SIGINT or SIGTERM. The first signal only cancels; a
+// second one gets the default handling, so a command that is not watching its
+// context can still be stopped.
 func Execute(root *Command, args []string, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ExecuteWith(root, args, Options{
+		Context: ctx,
+		Program: os.Args[0],
+		Stdin:   os.Stdin,
+		Stdout:  stdout,
+		Stderr:  stderr,
+	})
+}
+
+// ExecuteWith runs root against args using the streams in opts, so commands
+// can be driven in-process without touching the real process stdin.
+func ExecuteWith(root *Command, args []string, opts Options) int {
+	code, _ := execute(root, args, opts)
+	return code
+}
+
+// execute is ExecuteWith, also returning the error the run failed with, for
+// callers that report it somewhere other than stderr.
+func execute(root *Command, args []string, opts Options) (int, error) {
+	stdin, stdout, stderr := opts.Stdin, opts.Stdout, opts.Stderr
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	runCtx := opts.Context
+	if runCtx == nil {
+		runCtx = context.Background()
+	}
+
 	current := root
 	path := []string{root.Name}
+	var inherited []Flag
+	if sub := root.multicallTarget(opts.Program); sub != nil {
+		current = sub
+		path = []string{programName(opts.Program)}
+		inherited = append(inherited, root.PersistentFlags...)
+	}
+
+	// Config aliases load before the walk so they can stand in for the first
+	// word. A broken config only matters once a word is not a built-in.
+	var configMacros []Macro
+	var macroErr error
+	runCfg := opts.config
+	if root.Config {
+		if runCfg == nil || runCfg.file != configFlag(args) {
+			runCfg = loadRunConfig(root, configFlag(args))
+		}
+		configMacros, macroErr = runCfg.macros, runCfg.macroErr
+	}
+
+	if len(args) > 0 && args[0] == completeCommand {
+		writeCompletions(stdout, current, args[1:], configMacros)
+		return ExitOK, nil
+	}
+
 	remaining := args
+	values := make(map[string][]string)
+	expanded := make(map[string]bool)
+
+	// fail prints err and returns its exit code. Usage errors are followed by
+	// help, or by a pointer to it when the message already suggests a fix.
+	fail := func(err error) (int, error) {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		if KindOf(err) == KindUsage {
+			fmt.Fprintln(stderr)
+			fmt.Fprintln(stderr, current.help(path, configMacros))
+		}
+		return ExitCode(err), err
+	}
+	// showVersion renders the build info in the --output format when
+	// --version was given, reporting whether it did.
+	showVersion := func() (bool, error) {
+		if root.Version == nil || len(values["version"]) == 0 {
+			return false, nil
+		}
+		ctx := &Context{Root: root, Command: current, Path: path, Stdout: stdout, Stderr: stderr, flags: levelFlags(current, inherited, values)}
+		return true, ctx.Render(*root.Version)
+	}
+	failWithHint := func(err error) (int, error) {
+		fmt.Fprintf(stderr, "Error: %v\n\n", err)
+		fmt.Fprintf(stderr, "Use \"%s help\" for more information.\n", strings.Join(path, " "))
+		return ExitCode(err), err
+	}
 
 	for len(remaining) > 0 {
 		if isHelpArg(remaining[0]) {
-			fmt.Fprintln(stdout, current.Help(path))
-			return 0
+			fmt.Fprintln(stdout, current.help(path, configMacros))
+			return ExitOK, nil
+		}
+
+		// Flags between subcommand names apply at the level they appear.
+		if isFlagToken(remaining[0]) && len(current.Subcommands) > 0 {
+			n, err := levelFlags(current, inherited, values).parseOne(remaining)
+			if errors.Is(err, errHelp) {
+				fmt.Fprintln(stdout, current.help(path, configMacros))
+				return ExitOK, nil
+			}
+			if err != nil {
+				return fail(NewError(KindUsage, err))
+			}
+			remaining = remaining[n:]
+			continue
+		}
+
+		// Aliases, then plugins, take exact names only, ahead of prefix
+		// matching.
+		if current.findSubcommand(remaining[0]) == nil {
+			if current == root && macroErr != nil {
+				return fail(macroErr)
+			}
+			if macro, ok := current.findMacro(remaining[0], configMacros); ok {
+				if expanded[strings.ToLower(macro.Name)] {
+					return fail(Errorf(KindConfig, "alias %q expands to itself", macro.Name))
+				}
+				expanded[strings.ToLower(macro.Name)] = true
+				remaining = append(append([]string{}, macro.Args...), remaining[1:]...)
+				continue
+			}
+		}
+		if current == root && current.findSubcommand(remaining[0]) == nil {
+			if exe := root.findPlugin(remaining[0]); exe != "" {
+				err := runPlugin(runCtx, exe, remaining[1:], stdin, stdout, stderr)
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+					// The plugin printed its own error.
+					return exitErr.ExitCode(), fmt.Errorf("%s: %w", remaining[0], err)
+				}
+				if err != nil {
+					if runCtx.Err() != nil && KindOf(err) == KindFailure {
+						err = NewError(KindInterrupted, err)
+					}
+					return fail(err)
+				}
+				return ExitOK, nil
+			}
 		}
 
-		next := current.findSubcommand(remaining[0])
+		next, err := current.matchSubcommand(remaining[0])
+		if err != nil {
+			return failWithHint(NewError(KindUsage, err))
+		}
 		if next == nil {
 			break
 		}
 
+		inherited = append(inherited, current.PersistentFlags...)
 		current = next
 		path = append(path, current.Name)
 		remaining = remaining[1:]
 	}
 
+	if ok, err := showVersion(); ok {
+		if err != nil {
+			return fail(err)
+		}
+		return ExitOK, nil
+	}
+
 	if len(remaining) > 0 && len(current.Subcommands) > 0 && current.findSubcommand(remaining[0]) == nil {
-		fmt.Fprintf(stderr, "Error: unknown %s: %s\n\n", unknownLabel(path), remaining[0])
-		fmt.Fprintln(stderr, current.Help(path))
-		return 1
+		suggestion := didYouMean(current.suggestSubcommands(remaining[0]))
+		err := Errorf(KindUsage, "unknown %s: %s%s", unknownLabel(path), remaining[0], suggestion)
+		if suggestion != "" {
+			return failWithHint(err)
+		}
+		return fail(err)
 	}
 
 	if len(remaining) == 0 && len(current.Subcommands) > 0 && current.Run == nil && current.DefaultSubcommand != "" {
 		next := current.findSubcommand(current.DefaultSubcommand)
 		if next == nil {
-			fmt.Fprintf(stderr, "Error: unknown default %s: %s\n\n", unknownLabel(path), current.DefaultSubcommand)
-			fmt.Fprintln(stderr, current.Help(path))
-			return 1
+			return fail(Errorf(KindUsage, "unknown default %s: %s", unknownLabel(path), current.DefaultSubcommand))
 		}
+		inherited = append(inherited, current.PersistentFlags...)
 		current = next
 		path = append(path, current.Name)
 	}
 
 	if current.Run != nil {
+		flags := levelFlags(current, inherited, values)
+		positional, err := flags.parse(remaining)
+		if errors.Is(err, errHelp) {
+			fmt.Fprintln(stdout, current.help(path, configMacros))
+			return ExitOK, nil
+		}
+		if err != nil {
+			return fail(NewError(KindUsage, err))
+		}
+		if ok, err := showVersion(); ok {
+			if err != nil {
+				return fail(err)
+			}
+			return ExitOK, nil
+		}
+
+		// Utilities run on a broken config so it can be inspected and fixed.
+		var configFiles []string
+		if root.Config {
+			var file string
+			if given := values["config"]; len(given) > 0 {
+				file = given[0]
+			}
+			cfg, err := runCfg.cfg, runCfg.err
+			if file != runCfg.file {
+				cfg, err = loadConfig(root, file)
+			}
+			if err == nil {
+				configFiles = cfg.files()
+				err = cfg.apply(current, flags)
+			}
+			if err != nil && !current.isUtility() {
+				return fail(err)
+			}
+		}
+
+		piped := isPiped(opts.Stdin)
+		bound, err := bindArgs(current.Args, positional, piped)
+		if err != nil {
+			return fail(NewError(KindUsage, err))
+		}
+
 		ctx := &Context{
-			Command: current,
-			Path:    path,
-			Stdout:  stdout,
-			Stderr:  stderr,
+			Root:         root,
+			Command:      current,
+			Path:         path,
+			Stdin:        stdin,
+			Stdout:       stdout,
+			Stderr:       stderr,
+			flags:        flags,
+			args:         bound,
+			piped:        piped,
+			context:      runCtx,
+			configFiles:  configFiles,
+			configMacros: configMacros,
 		}
-		if err := current.Run(ctx, remaining); err != nil {
-			fmt.Fprintf(stderr, "Error: %v\n\n", err)
-			fmt.Fprintln(stderr, current.Help(path))
-			return 1
+		if err := runWithHooks(current, ctx, positional); err != nil {
+			// A child killed on cancellation reports its own failure.
+			if runCtx.Err() != nil && KindOf(err) == KindFailure {
+				err = NewError(KindInterrupted, err)
+			}
+			return fail(err)
 		}
-		return 0
+		return ExitOK, nil
 	}
 
 	if len(current.Subcommands) > 0 {
-		fmt.Fprintf(stderr, "Error: %s required\n\n", unknownLabel(path))
-		fmt.Fprintln(stderr, current.Help(path))
-		return 1
+		return fail(Errorf(KindUsage, "%s required", unknownLabel(path)))
 	}
 
 	if len(remaining) > 0 {
-		fmt.Fprintf(stderr, "Error: unexpected argument: %s\n\n", remaining[0])
-		fmt.Fprintln(stderr, current.Help(path))
-		return 1
+		return fail(Errorf(KindUsage, "unexpected argument: %s", remaining[0]))
 	}
 
-	return 0
+	return ExitOK, nil
 }
 
 func (c *Command) Help(path []string) string {
+	return c.help(path, nil)
+}
+
+// help renders the command's help, listing the aliases a run loaded from
+// config alongside the command's own.
+func (c *Command) help(path []string, configMacros []Macro) string {
 	cmdPath := strings.Join(path, " ")
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "%s - %s\n\n", cmdPath, c.Description)
 
 	b.WriteString("USAGE:\n")
-	if len(c.UsageLines) == 0 {
-		fmt.Fprintf(&b, "    %s%s\n", cmdPath, c.autoUsageSuffix())
-	} else {
-		for _, line := range c.UsageLines {
-			fmt.Fprintf(&b, "    %s\n", strings.ReplaceAll(line, "%cmd%", cmdPath))
-		}
+	for _, line := range c.usageLines(cmdPath) {
+		fmt.Fprintf(&b, "    %s\n", line)
 	}
 
 	if len(c.Subcommands) > 0 {
 		b.WriteString("\nCOMMANDS:\n")
+		plugins := c.discoverPlugins()
 		width := maxCommandWidth(c.Subcommands)
+		for _, p := range plugins {
+			width = max(width, len(p.name))
+		}
 		for _, sub := range c.Subcommands {
+			if sub.Hidden {
+				continue
+			}
 			name := sub.Name
 			if len(sub.Aliases) > 0 {
 				name = name + ", " + strings.Join(sub.Aliases, ", ")
 			}
 			fmt.Fprintf(&b, "    %-*s  %s\n", width, name, sub.Description)
 		}
+		for _, p := range plugins {
+			fmt.Fprintf(&b, "    %-*s  %s\n", width, p.name, p.description())
+		}
 		if c.DefaultSubcommand != "" {
 			fmt.Fprintf(&b, "\nDEFAULT:\n    %s\n", c.DefaultSubcommand)
 		}
 	}
 
-	if len(c.Flags) > 0 {
-		b.WriteString("\nFLAGS:\n")
-		width := maxFlagWidth(c.Flags)
-		for _, flag := range c.Flags {
-			fmt.Fprintf(&b, "    %-*s  %s\n", width, formatFlag(flag), flag.Description)
+	if macros := c.macros(configMacros); len(macros) > 0 {
+		b.WriteString("\nALIASES:\n")
+		width := 0
+		for _, macro := range macros {
+			width = max(width, len(macro.Name))
+		}
+		for _, macro := range macros {
+			fmt.Fprintf(&b, "    %-*s  %s\n", width, macro.Name, JoinArgs(macro.Args))
 		}
 	}
 
+	writeFlags := func(title string, flags []Flag) {
+		if len(flags) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		width := maxFlagWidth(flags)
+		for _, flag := range flags {
+			fmt.Fprintf(&b, "    %-*s  %s\n", width, formatFlag(flag), formatFlagDescription(flag))
+		}
+	}
+	writeFlags("FLAGS", c.ownFlags())
+	writeFlags("INHERITED FLAGS", c.inheritedFlags())
+
 	for _, section := range c.Sections {
 		if len(section.Lines) == 0 {
 			continue
 		}
 		fmt.Fprintf(&b, "\n%s:\n", section.Title)
 		for _, line := range section.Lines {
-			fmt.Fprintf(&b, "    %s\n", strings.ReplaceAll(line, "%cmd%", cmdPath))
+			fmt.Fprintf(&b, "    %s\n", expandPlaceholders(line, cmdPath))
 		}
 	}
 
-	if len(c.ExampleSpecs) > 0 || len(c.Examples) > 0 {
+	if examples := c.exampleLines(cmdPath); len(examples) > 0 {
 		b.WriteString("\nEXAMPLES:\n")
-		for _, example := range c.ExampleSpecs {
-			fmt.Fprintf(&b, "    %s\n", formatExampleLine(cmdPath, example))
-		}
-		for _, example := range c.Examples {
-			fmt.Fprintf(&b, "    %s\n", strings.ReplaceAll(example, "%cmd%", cmdPath))
+		for _, example := range examples {
+			fmt.Fprintf(&b, "    %s\n", example)
 		}
 	}
 
@@ -275,6 +575,62 @@ func (c *Command) Help(path []string) string {
 	return b.String()
 }
 
+func levelFlags(c *Command, inherited []Flag, values map[string][]string) *flagSet {
+	flags := append(append([]Flag{}, inherited...), c.ownFlags()...)
+	return &flagSet{flags: flags, values: values}
+}
+
+func (c *Command) ownFlags() []Flag {
+	return append(append([]Flag{}, c.Flags...), c.PersistentFlags...)
+}
+
+// inheritedFlags returns the persistent flags declared by the command's
+// ancestors, outermost first.
+func (c *Command) inheritedFlags() []Flag {
+	var chain []*Command
+	for p := c.parent; p != nil; p = p.parent {
+		chain = append(chain, p)
+	}
+	var flags []Flag
+	for i := len(chain) - 1; i >= 0; i-- {
+		flags = append(flags, chain[i].PersistentFlags...)
+	}
+	return flags
+}
+
+func (c *Command) usageLines(cmdPath string) []string {
+	if len(c.UsageLines) == 0 {
+		return []string{cmdPath + c.autoUsageSuffix()}
+	}
+	lines := make([]string, 0, len(c.UsageLines))
+	for _, line := range c.UsageLines {
+		lines = append(lines, expandPlaceholders(line, cmdPath))
+	}
+	return lines
+}
+
+// expandPlaceholders fills %cmd% in line with the command's path and %prog%
+// with the program name that path starts with.
+func expandPlaceholders(line, cmdPath string) string {
+	program, _, _ := strings.Cut(cmdPath, " ")
+	return strings.NewReplacer("%cmd%", cmdPath, "%prog%", program).Replace(line)
+}
+
+func (c *Command) exampleLines(cmdPath string) []string {
+	var lines []string
+	width := 0
+	for _, example := range c.ExampleSpecs {
+		width = max(width, len(formatExampleCommand(cmdPath, example)))
+	}
+	for _, example := range c.ExampleSpecs {
+		lines = append(lines, formatExampleLine(cmdPath, example, width))
+	}
+	for _, example := range c.Examples {
+		lines = append(lines, expandPlaceholders(example, cmdPath))
+	}
+	return lines
+}
+
 func (c *Command) findSubcommand(token string) *Command {
 	token = strings.ToLower(token)
 	for _, sub := range c.Subcommands {
@@ -345,6 +701,9 @@ func unknownLabel(path []string) string {
 func maxCommandWidth(commands []*Command) int {
 	max := 0
 	for _, command := range commands {
+		if command.Hidden {
+			continue
+		}
 		name := command.Name
 		if len(command.Aliases) > 0 {
 			name = name + ", " + strings.Join(command.Aliases, ", ")
@@ -357,9 +716,11 @@ func maxCommandWidth(commands []*Command) int {
 }
 
 func formatFlag(flag Flag) string {
+	value := flag.placeholder()
+
 	long := "--" + flag.Name
-	if flag.Value != "" {
-		long = long + " <" + flag.Value + ">"
+	if value != "" {
+		long = long + " <" + value + ">"
 	}
 
 	if flag.Short == "" {
@@ -367,12 +728,20 @@ func formatFlag(flag Flag) string {
 	}
 
 	short := "-" + flag.Short
-	if flag.Value != "" {
-		short = short + " <" + flag.Value + ">"
+	if value != "" {
+		short = short + " <" + value + ">"
 	}
 	return long + ", " + short
 }
 
+func formatFlagDescription(flag Flag) string {
+	description := flag.Description
+	if flag.Default != "" {
+		description = description + " (default: " + flag.Default + ")"
+	}
+	return description
+}
+
 func maxFlagWidth(flags []Flag) int {
 	max := 0
 	for _, flag := range flags {
@@ -384,141 +753,35 @@ func maxFlagWidth(flags []Flag) int {
 	return max
 }
 
-func validateCommand(c *Command, path []string, errs *[]string) {
-	cmdPath := strings.Join(path, " ")
-	if c.Name == "" {
-		*errs = append(*errs, fmt.Sprintf("command at %q has empty name", cmdPath))
-	}
-
-	if c.DefaultSubcommand != "" && c.findSubcommand(c.DefaultSubcommand) == nil {
-		*errs = append(*errs, fmt.Sprintf("command %q has unknown default subcommand %q", cmdPath, c.DefaultSubcommand))
-	}
-
-	seen := make(map[string]string)
-	addToken := func(token, kind string) {
-		if token == "" {
-			*errs = append(*errs, fmt.Sprintf("command %q has empty %s", cmdPath, kind))
-			return
-		}
-		key := strings.ToLower(token)
-		if prev, ok := seen[key]; ok {
-			*errs = append(*errs, fmt.Sprintf("command %q has duplicate token %q (%s conflicts with %s)", cmdPath, token, kind, prev))
-			return
-		}
-		seen[key] = kind
-	}
-
-	for _, sub := range c.Subcommands {
-		addToken(sub.Name, "subcommand")
-		for _, alias := range sub.Aliases {
-			addToken(alias, "alias")
-		}
-	}
-
-	for _, ex := range c.Examples {
-		validateExample(c, path, ex, errs)
-	}
-	for _, ex := range c.ExampleSpecs {
-		validateExampleSpec(c, path, ex, errs)
-	}
-
-	for _, sub := range c.Subcommands {
-		validateCommand(sub, append(path, sub.Name), errs)
-	}
-}
-
-func validateExample(c *Command, path []string, example string, errs *[]string) {
-	cmdPath := strings.Join(path, " ")
-	line := strings.TrimSpace(strings.ReplaceAll(example, "%cmd%", cmdPath))
-	if line == "" {
-		return
-	}
-	if i := strings.Index(line, " #"); i >= 0 {
-		line = strings.TrimSpace(line[:i])
-	}
-	if line == "" {
-		return
-	}
-
-	tokens := strings.Fields(line)
-	start := findPathStart(tokens, path)
-	if start < 0 {
-		*errs = append(*errs, fmt.Sprintf("example %q for %q does not include full command path", example, cmdPath))
-		return
-	}
-
-	current := c
-	currentPath := append([]string{}, path...)
-	remaining := tokens[start+len(path):]
-	for len(remaining) > 0 {
-		if isHelpArg(remaining[0]) {
-			return
-		}
-
-		next := current.findSubcommand(remaining[0])
-		if next == nil {
-			break
-		}
-		current = next
-		currentPath = append(currentPath, next.Name)
-		remaining = remaining[1:]
-	}
-
-	if len(remaining) > 0 && len(current.Subcommands) > 0 && current.findSubcommand(remaining[0]) == nil {
-		*errs = append(*errs, fmt.Sprintf("example %q for %q has unknown %s %q", example, cmdPath, unknownLabel(currentPath), remaining[0]))
-	}
-}
-
-func findPathStart(tokens []string, path []string) int {
-	if len(tokens) < len(path) {
-		return -1
+// formatExampleLine renders an example with its note, or its output when
+// that fits on one line, as a comment aligned to width.
+func formatExampleLine(cmdPath string, example Example, width int) string {
+	line := formatExampleCommand(cmdPath, example)
+	comment := example.Note
+	if comment == "" && !strings.Contains(example.Output, "\n") {
+		comment = example.Output
 	}
-	for i := 0; i <= len(tokens)-len(path); i++ {
-		ok := true
-		for j := 0; j < len(path); j++ {
-			if tokens[i+j] != path[j] {
-				ok = false
-				break
-			}
-		}
-		if ok {
-			return i
-		}
+	if comment == "" {
+		return line
 	}
-	return -1
+	return fmt.Sprintf("%-*s  # %s", width, line, comment)
 }
 
-func formatExampleLine(cmdPath string, example Example) string {
+func formatExampleCommand(cmdPath string, example Example) string {
 	line := cmdPath
 	if len(example.Args) > 0 {
 		line = line + " " + strings.Join(example.Args, " ")
 	}
-	if example.Note != "" {
-		line = line + "  # " + example.Note
+	if example.Stdin != "" {
+		line = "echo " + echoArg(example.Stdin) + " | " + line
 	}
 	return line
 }
 
-func validateExampleSpec(c *Command, path []string, example Example, errs *[]string) {
-	cmdPath := strings.Join(path, " ")
-	current := c
-	currentPath := append([]string{}, path...)
-	remaining := append([]string{}, example.Args...)
-
-	for len(remaining) > 0 {
-		if isHelpArg(remaining[0]) {
-			return
-		}
-		next := current.findSubcommand(remaining[0])
-		if next == nil {
-			break
-		}
-		current = next
-		currentPath = append(currentPath, next.Name)
-		remaining = remaining[1:]
-	}
-
-	if len(remaining) > 0 && len(current.Subcommands) > 0 && current.findSubcommand(remaining[0]) == nil {
-		*errs = append(*errs, fmt.Sprintf("structured example for %q has unknown %s %q", cmdPath, unknownLabel(currentPath), remaining[0]))
+// echoArg quotes s for display in an echo, the way examples are written.
+func echoArg(s string) string {
+	if !strings.ContainsAny(s, " \t\n'\"\\$`|&;<>()*?[]#~{}!") {
+		return s
 	}
+	return `"` + strings.NewReplacer(`"`, `\"`, `\`, `\\`, `$`, `\$`, "`", "\\`").Replace(s) + `"`
 }
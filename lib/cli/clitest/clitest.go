@@ -0,0 +1,192 @@
+// Package clitest runs command trees in-process and compares what they
+// print against golden files.
+//
+// Golden files live in testdata/<case>.golden. Run the tests with -update
+// to rewrite them from the current output.
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files from the current output")
+
+// Case is one invocation of a command tree. Stdin, when not empty, is
+// piped to the command. Env is set for the duration of the case. Context
+// carries fakes, such as a clipboard.Memory, to the commands.
+type Case struct {
+	Name    string
+	Args    []string
+	Stdin   string
+	Env     map[string]string
+	Context context.Context
+}
+
+// name returns the case's name, or one made from its arguments.
+func (c Case) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	name := unsafeName.ReplaceAllString(strings.Join(c.Args, "_"), "-")
+	if name == "" {
+		return "no-args"
+	}
+	return name
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Result is what a command printed and how it exited.
+type Result struct {
+	Args   []string
+	Stdin  string
+	Code   int
+	Stdout string
+	Stderr string
+}
+
+// String formats the result as a golden file.
+func (r Result) String() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace("$ "+cli.JoinArgs(r.Args)) + "\n")
+	b.WriteString("exit " + strconv.Itoa(r.Code) + "\n")
+	section(&b, "stdin", r.Stdin)
+	section(&b, "stdout", r.Stdout)
+	section(&b, "stderr", r.Stderr)
+	return b.String()
+}
+
+func section(b *strings.Builder, name, text string) {
+	if text == "" {
+		return
+	}
+	b.WriteString("-- " + name + " --\n")
+	b.WriteString(text)
+	if !strings.HasSuffix(text, "\n") {
+		b.WriteString("\n\\ no newline at end\n")
+	}
+}
+
+// Run executes root with the case's arguments and streams. The user config
+// directory is replaced by an empty one, and for roots built WithConfig the
+// working directory and the root's environment variables are cleared too, so
+// the developer's settings never leak into a test.
+func Run(t testing.TB, root *cli.Command, c Case) Result {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if root.Config {
+		prefix := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(root.Name)) + "_"
+		for _, kv := range os.Environ() {
+			if key, _, _ := strings.Cut(kv, "="); strings.HasPrefix(key, prefix) {
+				t.Setenv(key, "")
+			}
+		}
+		t.Chdir(t.TempDir())
+	}
+	for key, value := range c.Env {
+		t.Setenv(key, value)
+	}
+
+	opts := cli.Options{Context: c.Context}
+	if c.Stdin != "" {
+		opts.Stdin = strings.NewReader(c.Stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	opts.Stdout = &stdout
+	opts.Stderr = &stderr
+
+	code := cli.ExecuteWith(root, c.Args, opts)
+	return Result{Args: c.Args, Stdin: c.Stdin, Code: code, Stdout: stdout.String(), Stderr: stderr.String()}
+}
+
+// RunGolden runs each case as a subtest and compares its result against
+// testdata/<name>.golden.
+func RunGolden(t *testing.T, root *cli.Command, cases ...Case) {
+	t.Helper()
+	dir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.name(), func(t *testing.T) {
+			Golden(t, filepath.Join(dir, c.name()+".golden"), Run(t, root, c).String())
+		})
+	}
+}
+
+// Golden compares got against the file at path, or rewrites the file when
+// the tests run with -update.
+func Golden(t testing.TB, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run the tests with -update to create it", err)
+	}
+	if got != string(want) {
+		t.Fatalf("%s does not match; run the tests with -update to accept the change\n--- got ---\n%s--- want ---\n%s", path, got, want)
+	}
+}
+
+// RunExamples runs every structured example in root's tree that declares
+// Output as a subtest. An example passes when it exits 0 and prints exactly
+// its Output. Examples without Output are only documentation and may change
+// the machine, such as by writing config or installing links, so they are
+// skipped, as are External ones.
+func RunExamples(t *testing.T, root *cli.Command) {
+	t.Helper()
+	runExamples(t, root, root, nil)
+}
+
+func runExamples(t *testing.T, root, c *cli.Command, path []string) {
+	t.Helper()
+	for _, example := range c.ExampleSpecs {
+		name := strings.Join(append(append([]string{root.Name}, path...), example.Args...), " ")
+		t.Run(name, func(t *testing.T) {
+			if example.External {
+				t.Skip("depends on the environment")
+			}
+			if example.Output == "" {
+				t.Skip("declares no output")
+			}
+			args, err := example.Argv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := Case{Args: append(append([]string{}, path...), args...)}
+			if example.Stdin != "" {
+				c.Stdin = example.Stdin + "\n"
+			}
+
+			r := Run(t, root, c)
+			if r.Code != cli.ExitOK {
+				t.Fatalf("exit %d\n%s", r.Code, r.Stderr)
+			}
+			if strings.TrimSuffix(r.Stdout, "\n") != example.Output {
+				t.Fatalf("output = %q, want %q", strings.TrimSuffix(r.Stdout, "\n"), example.Output)
+			}
+		})
+	}
+	for _, sub := range c.Subcommands {
+		runExamples(t, root, sub, append(append([]string{}, path...), sub.Name))
+	}
+}
@@ -0,0 +1,345 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CompDirective tells the generated shell scripts what to do with the
+// candidates printed by the hidden __complete command.
+type CompDirective int
+
+const (
+	// CompleteDefault offers the candidates and falls back to file paths when there are none.
+	CompleteDefault CompDirective = iota
+	// CompleteNoFiles offers only the candidates.
+	CompleteNoFiles
+	// CompleteFiles offers file paths alongside any candidates.
+	CompleteFiles
+)
+
+// CompleteFunc returns dynamic candidates for the positional being typed.
+// args holds the positionals already on the command line.
+type CompleteFunc func(ctx *Context, args []string, toComplete string) ([]string, CompDirective)
+
+const completeCommand = "__complete"
+
+func (c *Command) WithComplete(complete CompleteFunc) *Command {
+	c.Complete = complete
+	return c
+}
+
+func (c *Command) Hide() *Command {
+	c.Hidden = true
+	return c
+}
+
+type candidate struct {
+	value       string
+	description string
+}
+
+// complete resolves words (everything after the program name, with the word
+// being typed last) against the tree and returns matching candidates.
+func complete(root *Command, words []string, configMacros []Macro) ([]candidate, CompDirective) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	toComplete := words[len(words)-1]
+	if toComplete == `""` || toComplete == `''` {
+		toComplete = ""
+	}
+	words = words[:len(words)-1]
+
+	current := root
+	path := []string{root.Name}
+	values := make(map[string][]string)
+	var inherited []Flag
+	flags := levelFlags(current, inherited, values)
+	var positional []string
+	terminated := false
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case terminated:
+			positional = append(positional, word)
+		case word == "--":
+			terminated = true
+		case isFlagToken(word):
+			n, err := flags.parseOne(words[i:])
+			if err != nil {
+				// A value-taking flag right before the word being typed.
+				if i == len(words)-1 && awaitsValue(flags, word) {
+					return nil, CompleteNoFiles
+				}
+				continue
+			}
+			i += n - 1
+		default:
+			if len(positional) == 0 {
+				if next, _ := current.matchSubcommand(word); next != nil {
+					inherited = append(inherited, current.PersistentFlags...)
+					current = next
+					path = append(path, current.Name)
+					flags = levelFlags(current, inherited, values)
+					continue
+				}
+			}
+			positional = append(positional, word)
+		}
+	}
+
+	if !terminated && strings.HasPrefix(toComplete, "-") {
+		return flagCandidates(flags.flags, toComplete), CompleteNoFiles
+	}
+
+	var out []candidate
+	directive := CompleteDefault
+	if len(positional) == 0 {
+		for _, sub := range current.Subcommands {
+			if sub.Hidden {
+				continue
+			}
+			if strings.HasPrefix(sub.Name, toComplete) {
+				out = append(out, candidate{value: sub.Name, description: sub.Description})
+			}
+		}
+		for _, macro := range current.macros(configMacros) {
+			if strings.HasPrefix(macro.Name, toComplete) {
+				out = append(out, candidate{value: macro.Name, description: "Alias for " + JoinArgs(macro.Args)})
+			}
+		}
+		if current == root {
+			for _, p := range root.discoverPlugins() {
+				if strings.HasPrefix(p.name, toComplete) {
+					out = append(out, candidate{value: p.name, description: p.description()})
+				}
+			}
+		}
+		if len(current.Subcommands) > 0 {
+			directive = CompleteNoFiles
+		}
+	}
+
+	target := current
+	if target.Run == nil && target.DefaultSubcommand != "" && len(positional) == 0 {
+		if next := target.findSubcommand(target.DefaultSubcommand); next != nil {
+			target = next
+		}
+	}
+	if target.Complete != nil {
+		ctx := &Context{Command: target, Path: path, flags: flags}
+		values, d := target.Complete(ctx, positional, toComplete)
+		for _, value := range values {
+			if strings.HasPrefix(value, toComplete) {
+				out = append(out, candidate{value: value})
+			}
+		}
+		directive = d
+	}
+
+	return out, directive
+}
+
+func awaitsValue(flags *flagSet, word string) bool {
+	if strings.HasPrefix(word, "--") {
+		if strings.Contains(word, "=") {
+			return false
+		}
+		flag := flags.lookup(word[2:])
+		return flag != nil && flag.takesValue()
+	}
+	flag := flags.lookupShort(word[len(word)-1:])
+	return flag != nil && flag.takesValue()
+}
+
+func flagCandidates(flags []Flag, toComplete string) []candidate {
+	var out []candidate
+	for _, flag := range append(append([]Flag{}, flags...), helpFlag) {
+		long := "--" + flag.Name
+		if strings.HasPrefix(long, toComplete) {
+			out = append(out, candidate{value: long, description: flag.Description})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].value < out[j].value })
+	return out
+}
+
+func writeCompletions(w io.Writer, root *Command, words []string, configMacros []Macro) {
+	candidates, directive := complete(root, words, configMacros)
+	for _, c := range candidates {
+		if c.description != "" {
+			fmt.Fprintf(w, "%s\t%s\n", c.value, c.description)
+		} else {
+			fmt.Fprintln(w, c.value)
+		}
+	}
+	fmt.Fprintf(w, ":%d\n", directive)
+}
+
+// NewCompletionCommand returns a command that prints completion scripts for
+// the program it is registered under.
+func NewCompletionCommand(name string) *Command {
+	shell := func(shellName, description string, script func(program string) string) *Command {
+		return New(shellName, description).
+			WithRun(func(ctx *Context, args []string) error {
+				_, err := io.WriteString(ctx.Stdout, script(ctx.Path[0]))
+				return err
+			})
+	}
+
+	return New(name, "Generate shell completion scripts").
+		WithSubcommandName("shell").
+		WithSections(Section{
+			Title: "INSTALL",
+			Lines: []string{
+				"bash:        source <(%cmd% bash)",
+				"zsh:         %cmd% zsh > \"${fpath[1]}/_%prog%\"",
+				"fish:        %cmd% fish > ~/.config/fish/completions/%prog%.fish",
+				"powershell:  %cmd% powershell | Out-String | Invoke-Expression",
+			},
+		}).
+		Register(
+			shell("bash", "Generate a bash completion script", bashCompletion),
+			shell("zsh", "Generate a zsh completion script", zshCompletion),
+			shell("fish", "Generate a fish completion script", fishCompletion),
+			shell("powershell", "Generate a PowerShell completion script", powershellCompletion).WithAliases("pwsh"),
+		).
+		AsUtility()
+}
+
+func shellFuncName(program string) string {
+	return "__" + strings.NewReplacer("-", "_", ".", "_").Replace(program) + "_complete"
+}
+
+func bashCompletion(program string) string {
+	fn := shellFuncName(program)
+	return fmt.Sprintf(`# bash completion for %[1]s
+%[2]s() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local out
+    out=$(%[1]s %[3]s "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null) || return
+
+    local IFS=$'\n'
+    local -a lines=($out)
+    local count=${#lines[@]}
+    (( count > 0 )) || return
+    local directive=${lines[count-1]#:}
+    unset "lines[count-1]"
+
+    COMPREPLY=()
+    local line
+    for line in "${lines[@]}"; do
+        COMPREPLY+=("${line%%%%$'\t'*}")
+    done
+
+    if [[ $directive == 2 || ( $directive == 0 && ${#COMPREPLY[@]} -eq 0 ) ]]; then
+        compopt -o filenames 2>/dev/null
+        COMPREPLY+=($(compgen -f -- "$cur"))
+    fi
+}
+complete -F %[2]s %[1]s
+`, program, fn, completeCommand)
+}
+
+func zshCompletion(program string) string {
+	fn := shellFuncName(program)
+	return fmt.Sprintf(`#compdef %[1]s
+# zsh completion for %[1]s
+%[2]s() {
+    local out directive line
+    local -a lines completions
+    out=$(%[1]s %[3]s "${(@)words[2,CURRENT]}" 2>/dev/null) || return
+
+    lines=("${(@f)out}")
+    directive=${lines[-1]#:}
+    lines=("${(@)lines[1,-2]}")
+
+    for line in $lines; do
+        local value=${line%%%%$'\t'*}
+        local desc=${line#*$'\t'}
+        value=${value//:/\\:}
+        if [[ $line == *$'\t'* ]]; then
+            completions+=("$value:$desc")
+        else
+            completions+=("$value")
+        fi
+    done
+
+    if (( ${#completions} )); then
+        _describe -t values '%[1]s' completions
+    fi
+    if [[ $directive == 2 || ( $directive == 0 && ${#completions} -eq 0 ) ]]; then
+        _files
+    fi
+}
+
+if [[ "${funcstack[1]}" == "_%[1]s" ]]; then
+    %[2]s "$@"
+else
+    compdef %[2]s %[1]s
+fi
+`, program, fn, completeCommand)
+}
+
+func fishCompletion(program string) string {
+	fn := shellFuncName(program)
+	return fmt.Sprintf(`# fish completion for %[1]s
+function %[2]s
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l out (%[1]s %[3]s $words (commandline -ct) 2>/dev/null)
+    test (count $out) -gt 0; or return
+
+    set -l directive (string replace ':' '' -- $out[-1])
+    set -e out[-1]
+    for line in $out
+        echo $line
+    end
+
+    if test "$directive" = 2; or begin; test "$directive" = 0; and test (count $out) -eq 0; end
+        __fish_complete_path (commandline -ct)
+    end
+end
+complete -c %[1]s -f -a '(%[2]s)'
+`, program, fn, completeCommand)
+}
+
+func powershellCompletion(program string) string {
+	return fmt.Sprintf(`# PowerShell completion for %[1]s
+Register-ArgumentCompleter -Native -CommandName '%[1]s' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.StartOffset -lt $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        $words += '""'
+    }
+
+    $out = @(& '%[1]s' '%[2]s' @words 2>$null)
+    if ($out.Count -eq 0) {
+        return
+    }
+
+    $directive = [int]($out[-1].TrimStart(':'))
+    $lines = @($out | Select-Object -First ($out.Count - 1))
+    if ($lines.Count -eq 0 -and $directive -ne 1) {
+        # Returning nothing lets PowerShell fall back to path completion.
+        return
+    }
+
+    foreach ($line in $lines) {
+        $value, $desc = $line -split "`+"`t"+`", 2
+        if (-not $desc) {
+            $desc = $value
+        }
+        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $desc)
+    }
+}
+`, program, completeCommand)
+}
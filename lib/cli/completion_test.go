@@ -0,0 +1,133 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func newCompletionTree() *cli.Command {
+	return cli.New("tool", "Completion test tool").
+		WithSubcommandName("command").
+		Register(
+			cli.New("paint", "Paint things").
+				WithAliases("p").
+				WithArgs(cli.OptionalArg("format"), cli.VariadicArg("color")).
+				RegisterFlags(
+					cli.BoolFlag("all", "a", "Everything"),
+					cli.StringFlag("palette", "P", "name", "Palette to use"),
+				).
+				WithComplete(func(ctx *cli.Context, args []string, toComplete string) ([]string, cli.CompDirective) {
+					if len(args) == 0 {
+						return []string{"hex", "rgb", "red"}, cli.CompleteNoFiles
+					}
+					return []string{"red", "rose"}, cli.CompleteNoFiles
+				}).
+				WithRun(noop),
+			cli.New("play", "Play a file").
+				WithArgs(cli.RequiredArg("file")).
+				WithComplete(func(ctx *cli.Context, args []string, toComplete string) ([]string, cli.CompDirective) {
+					return nil, cli.CompleteFiles
+				}).
+				WithRun(noop),
+			cli.New("secret", "Hidden command").Hide().WithRun(noop),
+			cli.NewCompletionCommand("completion"),
+		)
+}
+
+func TestCompleteCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{
+			name:  "subcommands skip hidden",
+			words: []string{""},
+			want:  "paint\tPaint things\nplay\tPlay a file\ncompletion\tGenerate shell completion scripts\n:1\n",
+		},
+		{
+			name:  "subcommand prefix",
+			words: []string{"pl"},
+			want:  "play\tPlay a file\n:1\n",
+		},
+		{
+			name:  "dynamic first positional via alias",
+			words: []string{"p", "r"},
+			want:  "rgb\nred\n:1\n",
+		},
+		{
+			name:  "dynamic later positional skips flags",
+			words: []string{"paint", "-a", "--palette", "web", "hex", "r"},
+			want:  "red\nrose\n:1\n",
+		},
+		{
+			name:  "flag names",
+			words: []string{"paint", "--p"},
+			want:  "--palette\tPalette to use\n:1\n",
+		},
+		{
+			name:  "flag value",
+			words: []string{"paint", "--palette", ""},
+			want:  ":1\n",
+		},
+		{
+			name:  "file directive",
+			words: []string{"play", ""},
+			want:  ":2\n",
+		},
+		{
+			name:  "powershell empty word",
+			words: []string{"completion", `""`},
+			want:  "bash\tGenerate a bash completion script\nzsh\tGenerate a zsh completion script\nfish\tGenerate a fish completion script\npowershell\tGenerate a PowerShell completion script\n:1\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"__complete"}, tc.words...)
+			if code := cli.Execute(newCompletionTree(), args, &stdout, &stderr); code != 0 {
+				t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+			}
+			if got := stdout.String(); got != tc.want {
+				t.Fatalf("__complete %q =\n%q\nwant\n%q", tc.words, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := cli.Execute(newCompletionTree(), []string{"completion", shell}, &stdout, &stderr); code != 0 {
+				t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+			}
+			script := stdout.String()
+			if !strings.Contains(script, "tool __complete") && !strings.Contains(script, "'tool' '__complete'") {
+				t.Fatalf("%s script does not call back into the program:\n%s", shell, script)
+			}
+		})
+	}
+}
+
+func TestHelpSkipsHiddenCommands(t *testing.T) {
+	help := newCompletionTree().Help([]string{"tool"})
+	if strings.Contains(help, "secret") {
+		t.Fatalf("Help() lists hidden command:\n%s", help)
+	}
+}
+
+func TestCompletionInstallNamesProgram(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cli.Execute(newCompletionTree(), []string{"completion", "--help"}, &stdout, &stderr)
+	help := stdout.String()
+	for _, want := range []string{`tool completion zsh > "${fpath[1]}/_tool"`, "tool completion fish > ~/.config/fish/completions/tool.fish"} {
+		if !strings.Contains(help, want) {
+			t.Fatalf("Help() lacks %q:\n%s", want, help)
+		}
+	}
+}
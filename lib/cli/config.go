@@ -0,0 +1,658 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// WithConfig makes a root command read flag defaults from config files and
+// <NAME>_* environment variables, adds a --config flag that swaps the user
+// file for another, and documents the lookup order in its help.
+func (c *Command) WithConfig() *Command {
+	c.Config = true
+	c.PersistentFlags = append(c.PersistentFlags,
+		StringFlag("config", "", "file", "Read defaults from file instead of the user config").WithoutConfig(),
+	)
+	c.Sections = append(c.Sections, ConfigSection(c.Name))
+	return c
+}
+
+// ConfigSection documents where a root command named name reads its flag
+// defaults from.
+func ConfigSection(name string) Section {
+	env := envName(name)
+	return Section{
+		Title: "CONFIGURATION",
+		Lines: []string{
+			"Flag defaults come from the first of these that sets them:",
+			"  1. flags on the command line",
+			"  2. " + env + "_<COMMAND>_<FLAG> environment variables, e.g. " + env + "_OUTPUT",
+			"  3. ." + name + ".toml in the current directory or the nearest parent with one",
+			"  4. ~/.config/" + name + "/config.toml ($XDG_CONFIG_HOME, --config or " + env + "_CONFIG move it)",
+			"  5. built-in defaults",
+			"Tables are command paths and keys are flag names; a table's keys also",
+			"apply to its subcommands. The [alias] table defines new commands:",
+			"  output = \"json\"",
+			"  [<command>.<subcommand>]",
+			"  <flag> = \"value\"",
+			"  [alias]",
+			"  <name> = \"<command> [arguments]\"",
+		},
+	}
+}
+
+// configSetting is a configured flag value and where it came from.
+type configSetting struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+	Source string   `json:"source"`
+}
+
+func (s configSetting) Text() string {
+	return strings.Join(s.Values, "\n")
+}
+
+type configSettings []configSetting
+
+func (s configSettings) Text() string {
+	keyWidth, valueWidth := 0, 0
+	for _, setting := range s {
+		keyWidth = max(keyWidth, len(setting.Key))
+		valueWidth = max(valueWidth, len(strings.Join(setting.Values, ", ")))
+	}
+	lines := make([]string, len(s))
+	for i, setting := range s {
+		lines[i] = fmt.Sprintf("%-*s  %-*s  %s", keyWidth, setting.Key, valueWidth, strings.Join(setting.Values, ", "), setting.Source)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// configPaths are the files a root command reads its config from. The
+// project file is the nearest one in the working directory or above it.
+type configPaths struct {
+	User       string `json:"user"`
+	UserExists bool   `json:"user_exists"`
+	Project    string `json:"project,omitempty"`
+
+	explicit bool
+}
+
+func (p configPaths) Text() string {
+	text := "user:    " + p.User
+	if !p.UserExists {
+		text += " (not found)"
+	}
+	if p.Project != "" {
+		text += "\nproject: " + p.Project
+	}
+	return text
+}
+
+// findConfigPaths locates the user file, which file (from --config) or the
+// <NAME>_CONFIG variable replace, and the nearest project file.
+func findConfigPaths(root *Command, file string) (configPaths, error) {
+	if file == "" {
+		file = os.Getenv(envName(root.Name) + "_CONFIG")
+	}
+	paths := configPaths{User: file, explicit: file != ""}
+	if paths.User == "" {
+		dir, err := userConfigDir()
+		if err != nil {
+			return paths, NewError(KindConfig, err)
+		}
+		paths.User = filepath.Join(dir, root.Name, "config.toml")
+	}
+	if info, err := os.Stat(paths.User); err == nil && !info.IsDir() {
+		paths.UserExists = true
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return paths, nil
+	}
+	for {
+		path := filepath.Join(dir, "."+root.Name+".toml")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			paths.Project = path
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return paths, nil
+}
+
+// userConfigDir follows XDG on every platform but Windows, where it falls
+// back to %AppData%.
+func userConfigDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return os.UserConfigDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config"), nil
+}
+
+type configLayer struct {
+	path string
+	doc  *tomlDoc // nil for the environment
+}
+
+// config is every source of flag defaults, highest precedence first.
+type config struct {
+	env    string
+	layers []configLayer
+}
+
+func loadConfig(root *Command, file string) (*config, error) {
+	paths, err := findConfigPaths(root, file)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config{env: envName(root.Name), layers: []configLayer{{}}}
+	if paths.Project != "" {
+		doc, err := readConfigFile(paths.Project, true)
+		if err != nil {
+			return nil, err
+		}
+		cfg.layers = append(cfg.layers, configLayer{path: paths.Project, doc: doc})
+	}
+	doc, err := readConfigFile(paths.User, paths.explicit)
+	if err != nil {
+		return nil, err
+	}
+	if doc != nil {
+		cfg.layers = append(cfg.layers, configLayer{path: paths.User, doc: doc})
+	}
+	return cfg, nil
+}
+
+// runConfig is the config a run starts from: the files read for file, the
+// --config value, and the aliases they define. Batch and serve load it once
+// for all of their requests.
+type runConfig struct {
+	file     string
+	cfg      *config
+	err      error
+	macros   []Macro
+	macroErr error
+}
+
+func loadRunConfig(root *Command, file string) *runConfig {
+	rc := &runConfig{file: file}
+	rc.cfg, rc.err = loadConfig(root, file)
+	if rc.err != nil {
+		rc.macroErr = rc.err
+		return rc
+	}
+	rc.macros, rc.macroErr = loadConfigMacros(root, rc.cfg)
+	return rc
+}
+
+// requestConfig loads the config shared by the requests batch and serve run
+// against root, which give no --config of their own.
+func requestConfig(root *Command) *runConfig {
+	if !root.Config {
+		return nil
+	}
+	return loadRunConfig(root, "")
+}
+
+// files returns the config files read, highest precedence first.
+func (c *config) files() []string {
+	var files []string
+	for _, layer := range c.layers {
+		if layer.doc != nil {
+			files = append(files, layer.path)
+		}
+	}
+	return files
+}
+
+// ConfigFiles returns the config files that supplied flag defaults to this
+// run, highest precedence first.
+func (c *Context) ConfigFiles() []string {
+	return append([]string(nil), c.configFiles...)
+}
+
+// readConfigFile parses path. A missing file reads as nil unless required.
+func readConfigFile(path string, required bool) (*tomlDoc, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, NewError(KindConfig, err)
+	}
+	doc, err := parseTOML(string(b))
+	if err != nil {
+		return nil, Errorf(KindConfig, "%s: %v", path, err)
+	}
+	return doc, nil
+}
+
+// lookup finds the configured values for flag on the command at path.
+// Layers are tried in precedence order; within a layer the command's own
+// table wins over its ancestors'.
+func (c *config) lookup(path []string, flag Flag) (configSetting, bool) {
+	for _, layer := range c.layers {
+		for i := len(path); i >= 0; i-- {
+			key := configKey(path[:i], flag.Name)
+			if layer.doc == nil {
+				name := c.envVar(key)
+				value := os.Getenv(name)
+				if value == "" {
+					continue
+				}
+				values := []string{value}
+				if flag.Repeatable {
+					values = splitList(value)
+				}
+				return configSetting{Key: key, Values: values, Source: "$" + name}, true
+			}
+			if values, ok := layer.doc.lookup(strings.Join(path[:i], "."), flag.Name); ok {
+				return configSetting{Key: key, Values: values, Source: layer.path}, true
+			}
+		}
+	}
+	return configSetting{}, false
+}
+
+// lookupAlias finds the file that defines the named alias.
+func (c *config) lookupAlias(name string) (configSetting, bool) {
+	for _, layer := range c.layers {
+		if layer.doc == nil {
+			continue
+		}
+		if values, ok := layer.doc.lookup(aliasTable, name); ok {
+			return configSetting{Key: aliasTable + "." + name, Values: values, Source: layer.path}, true
+		}
+	}
+	return configSetting{}, false
+}
+
+// apply fills flags the command line left unset from config.
+func (c *config) apply(cmd *Command, flags *flagSet) error {
+	path := cmd.configPath()
+	for _, flag := range flags.flags {
+		if flag.NoConfig {
+			continue
+		}
+		if _, ok := flags.values[flag.Name]; ok {
+			continue
+		}
+		setting, ok := c.lookup(path, flag)
+		if !ok {
+			continue
+		}
+		if err := checkConfigValues(flag, setting.Values); err != nil {
+			return Errorf(KindConfig, "%s: %s: %v", setting.Source, setting.Key, err)
+		}
+		if flags.configured == nil {
+			flags.configured = make(map[string][]string)
+		}
+		flags.configured[flag.Name] = setting.Values
+	}
+	return nil
+}
+
+// settings lists every setting in effect, reporting keys that match no flag
+// as unknown.
+func (c *config) settings(root *Command) (configSettings, []string) {
+	seen := make(map[string]bool)
+	var settings configSettings
+	var unknown []string
+	add := func(setting configSetting) {
+		if !seen[setting.Key] {
+			seen[setting.Key] = true
+			settings = append(settings, setting)
+		}
+	}
+
+	for _, layer := range c.layers {
+		if layer.doc == nil {
+			for _, target := range configTargets(root) {
+				name := c.envVar(target.key)
+				if value := os.Getenv(name); value != "" {
+					values := []string{value}
+					if target.flag.Repeatable {
+						values = splitList(value)
+					}
+					add(configSetting{Key: target.key, Values: values, Source: "$" + name})
+				}
+			}
+			continue
+		}
+		for _, table := range layer.doc.tables {
+			for _, entry := range table.entries {
+				key := joinTOMLKey(table.name, entry.key)
+				if strings.HasPrefix(key, aliasTable+".") {
+					add(configSetting{Key: key, Values: entry.values, Source: layer.path})
+					continue
+				}
+				target, err := resolveConfigKey(root, key)
+				if err != nil || target.key != key {
+					unknown = append(unknown, layer.path+": "+key)
+					continue
+				}
+				add(configSetting{Key: target.key, Values: entry.values, Source: layer.path})
+			}
+		}
+	}
+
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings, unknown
+}
+
+func (c *config) envVar(key string) string {
+	return c.env + "_" + envName(key)
+}
+
+func envName(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(s))
+}
+
+func configKey(path []string, flag string) string {
+	return strings.Join(append(append([]string{}, path...), flag), ".")
+}
+
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func checkConfigValues(flag Flag, values []string) error {
+	if !flag.Repeatable && len(values) != 1 {
+		return errors.New("takes a single value, not a list")
+	}
+	for _, value := range values {
+		if err := flag.check(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configPath returns the names of the commands from the root down to c,
+// leaving out the root itself.
+func (c *Command) configPath() []string {
+	var path []string
+	for p := c; p.parent != nil; p = p.parent {
+		path = append([]string{p.Name}, path...)
+	}
+	return path
+}
+
+type configTarget struct {
+	key  string
+	path []string
+	flag Flag
+}
+
+// configTargets lists a key for every configurable flag in the tree, on the
+// command that declares it.
+func configTargets(root *Command) []configTarget {
+	var targets []configTarget
+	var walk func(c *Command, path []string)
+	walk = func(c *Command, path []string) {
+		for _, flag := range c.ownFlags() {
+			if !flag.NoConfig {
+				targets = append(targets, configTarget{key: configKey(path, flag.Name), path: path, flag: flag})
+			}
+		}
+		for _, sub := range c.Subcommands {
+			if !sub.Hidden {
+				walk(sub, append(path[:len(path):len(path)], sub.Name))
+			}
+		}
+	}
+	walk(root, nil)
+	return targets
+}
+
+// resolveConfigKey checks a dotted key against the tree and returns it with
+// command names canonicalized. A key may name a flag its command inherits or
+// one its subcommands declare.
+func resolveConfigKey(root *Command, key string) (configTarget, error) {
+	unknown := func() error {
+		var keys []string
+		for _, target := range configTargets(root) {
+			keys = append(keys, target.key)
+		}
+		return Errorf(KindUsage, "unknown config key: %s%s", key, DidYouMean(key, keys))
+	}
+
+	parts := strings.Split(key, ".")
+	name := parts[len(parts)-1]
+	cmd := root
+	var path []string
+	for _, part := range parts[:len(parts)-1] {
+		sub := cmd.findSubcommand(part)
+		if sub == nil {
+			return configTarget{}, unknown()
+		}
+		cmd = sub
+		path = append(path, sub.Name)
+	}
+
+	flags := append(cmd.inheritedFlags(), cmd.ownFlags()...)
+	for _, target := range configTargets(cmd) {
+		flags = append(flags, target.flag)
+	}
+	for _, flag := range flags {
+		if flag.Name != name {
+			continue
+		}
+		if flag.NoConfig {
+			return configTarget{}, Errorf(KindUsage, "--%s cannot be set in config", name)
+		}
+		return configTarget{key: configKey(path, name), path: path, flag: flag}, nil
+	}
+	return configTarget{}, unknown()
+}
+
+// formatConfigValue writes values as TOML for flag.
+func formatConfigValue(flag Flag, values []string) string {
+	scalar := func(value string) string {
+		switch flag.Kind() {
+		case FlagBool, FlagInt, FlagFloat:
+			return value
+		default:
+			return tomlString(value)
+		}
+	}
+	if !flag.Repeatable {
+		return scalar(values[0])
+	}
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = scalar(value)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// NewConfigCommand returns a command for reading and editing the config of a
+// root command built WithConfig.
+func NewConfigCommand(name string) *Command {
+	load := func(ctx *Context) (*config, error) {
+		return loadConfig(ctx.Root, ctx.String("config"))
+	}
+
+	get := New("get", "Show the value in effect for a key and where it comes from").
+		WithArgs(RequiredArg("key")).
+		WithExample("Show the configured output format", "output").
+		WithExample("Show the reserved slugs", "slug.reserved").
+		WithExample("Show what an alias runs", "alias.title").
+		WithComplete(completeConfigKeys).
+		WithRun(func(ctx *Context, args []string) error {
+			if name, ok := strings.CutPrefix(ctx.Arg("key"), aliasTable+"."); ok {
+				cfg, err := load(ctx)
+				if err != nil {
+					return err
+				}
+				setting, ok := cfg.lookupAlias(name)
+				if !ok {
+					return Errorf(KindNotFound, "alias %s is not set", name)
+				}
+				return ctx.Render(setting)
+			}
+
+			target, err := resolveConfigKey(ctx.Root, ctx.Arg("key"))
+			if err != nil {
+				return err
+			}
+			cfg, err := load(ctx)
+			if err != nil {
+				return err
+			}
+			setting, ok := cfg.lookup(target.path, target.flag)
+			if !ok {
+				if target.flag.Default == "" {
+					return Errorf(KindNotFound, "%s is not set", target.key)
+				}
+				setting = configSetting{Key: target.key, Values: []string{target.flag.Default}, Source: "default"}
+			}
+			return ctx.Render(setting)
+		})
+
+	set := New("set", "Write a key to the user config, or the project config with --project").
+		WithArgs(RequiredArg("key"), VariadicArg("value")).
+		RegisterFlags(
+			BoolFlag("project", "p", "Write to the project config, creating one here if there is none"),
+		).
+		WithExample("Default to JSON output", "output", "json").
+		WithExample("Always reserve these slugs", "slug.reserved", "admin", "api").
+		WithExample("Prefer a clipboard backend in this project", "--project", "clip.backend", "xsel").
+		WithExample("Add an alias for a command line", "alias.title", "\"case title --style chicago\"").
+		WithComplete(completeConfigKeys).
+		WithRun(func(ctx *Context, args []string) error {
+			table, name, value, err := configAssignment(ctx.Root, ctx.Arg("key"), ctx.Variadic("value"))
+			if err != nil {
+				return err
+			}
+
+			paths, err := findConfigPaths(ctx.Root, ctx.String("config"))
+			if err != nil {
+				return err
+			}
+			path := paths.User
+			if ctx.Bool("project") {
+				path = paths.Project
+				if path == "" {
+					path = "." + ctx.Root.Name + ".toml"
+				}
+			}
+
+			doc, err := readConfigFile(path, false)
+			if err != nil {
+				return err
+			}
+			if doc == nil {
+				doc = &tomlDoc{}
+			}
+			doc.set(table, name, value)
+
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			return os.WriteFile(path, []byte(doc.String()), 0o644)
+		})
+
+	list := New("list", "List every configured key with its value and source").
+		WithAliases("ls").
+		WithExample("List the config in effect").
+		WithRun(func(ctx *Context, args []string) error {
+			cfg, err := load(ctx)
+			if err != nil {
+				return err
+			}
+			settings, unknown := cfg.settings(ctx.Root)
+			for _, key := range unknown {
+				fmt.Fprintf(ctx.Stderr, "warning: unknown config key %s\n", key)
+			}
+			if settings == nil {
+				settings = configSettings{}
+			}
+			return ctx.Render(settings)
+		})
+
+	path := New("path", "Show the config files that are read").
+		WithExample("Show the config file locations").
+		WithRun(func(ctx *Context, args []string) error {
+			paths, err := findConfigPaths(ctx.Root, ctx.String("config"))
+			if err != nil {
+				return err
+			}
+			return ctx.Render(paths)
+		})
+
+	return New(name, "Show and edit configured flag defaults").
+		WithSubcommandName("action").
+		WithExample("Show where config is read from", "path").
+		WithExample("Set the default output format", "set", "output", "json").
+		Register(get, set, list, path).
+		AsUtility()
+}
+
+// configAssignment checks a key and values for config set and returns the
+// table, key and TOML value to write.
+func configAssignment(root *Command, key string, values []string) (string, string, string, error) {
+	if name, ok := strings.CutPrefix(key, aliasTable+"."); ok {
+		args, err := macroArgs(values)
+		if err == nil {
+			err = checkMacro(root, Macro{Name: name, Args: args})
+		}
+		if err != nil {
+			return "", "", "", Errorf(KindUsage, "%s: %v", key, err)
+		}
+		flag := StringFlag(name, "", "", "")
+		if len(values) > 1 {
+			flag = flag.Repeated()
+		}
+		return aliasTable, name, formatConfigValue(flag, values), nil
+	}
+
+	target, err := resolveConfigKey(root, key)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err := checkConfigValues(target.flag, values); err != nil {
+		return "", "", "", Errorf(KindUsage, "%s: %v", target.key, err)
+	}
+	return strings.Join(target.path, "."), target.flag.Name, formatConfigValue(target.flag, values), nil
+}
+
+func completeConfigKeys(ctx *Context, args []string, toComplete string) ([]string, CompDirective) {
+	if len(args) > 0 {
+		return nil, CompleteDefault
+	}
+	root := ctx.Command
+	for root.parent != nil {
+		root = root.parent
+	}
+	var keys []string
+	for _, target := range configTargets(root) {
+		keys = append(keys, target.key)
+	}
+	return keys, CompleteNoFiles
+}
@@ -0,0 +1,305 @@
+package cli_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+// newConfigRoot returns a tool whose commands print their flag values, with
+// an empty user config dir and working directory.
+func newConfigRoot(t *testing.T) (*cli.Command, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+	t.Setenv("TOOL_CONFIG", "")
+	work := filepath.Join(dir, "work", "sub")
+	if err := os.MkdirAll(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(work)
+
+	show := func(ctx *cli.Context, args []string) error {
+		return ctx.Render(ctx.String("style") + "|" + strings.Join(ctx.Strings("reserved"), ",") + "|" + ctx.String("output"))
+	}
+	root := cli.New("tool", "Config test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		WithConfig().
+		Register(
+			cli.New("case", "Convert case").
+				RegisterFlags(cli.StringFlag("style", "", "style", "Title style").WithDefault("apa")).
+				WithRun(show),
+			cli.New("slug", "Slug text").
+				RegisterFlags(cli.StringFlag("reserved", "r", "slug", "Reserve a slug").Repeated()).
+				WithRun(show),
+			cli.New("clip", "Clipboard").Register(
+				cli.New("copy", "Copy").
+					RegisterFlags(cli.StringFlag("backend", "", "name", "Backend")).
+					WithRun(func(ctx *cli.Context, args []string) error { return ctx.Render(ctx.String("backend")) }),
+			),
+			cli.NewConfigCommand("config"),
+		)
+	return root, dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func run(t *testing.T, root *cli.Command, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(root, args, cli.Options{Stdout: &stdout, Stderr: &stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		project string
+		env     map[string]string
+		args    []string
+		want    string
+	}{
+		{name: "defaults", args: []string{"case"}, want: "apa||text\n"},
+		{name: "user", user: "[case]\nstyle = \"chicago\"\n", args: []string{"case"}, want: "chicago||text\n"},
+		{name: "top level applies everywhere", user: "output = \"tsv\"\n", args: []string{"case"}, want: "apa||tsv\n"},
+		{
+			name:    "project over user",
+			user:    "[case]\nstyle = \"chicago\"\n",
+			project: "[case]\nstyle = \"mla\"\n",
+			args:    []string{"case"},
+			want:    "mla||text\n",
+		},
+		{
+			name:    "env over project",
+			project: "[case]\nstyle = \"mla\"\n",
+			env:     map[string]string{"TOOL_CASE_STYLE": "ap"},
+			args:    []string{"case"},
+			want:    "ap||text\n",
+		},
+		{
+			name: "flag over env",
+			env:  map[string]string{"TOOL_CASE_STYLE": "ap"},
+			args: []string{"case", "--style", "ama"},
+			want: "ama||text\n",
+		},
+		{name: "list", user: "[slug]\nreserved = [\n  \"admin\", # first\n  \"api\",\n]\n", args: []string{"slug"}, want: "|admin,api|text\n"},
+		{name: "env list", env: map[string]string{"TOOL_SLUG_RESERVED": "a, b"}, args: []string{"slug"}, want: "|a,b|text\n"},
+		{name: "flags replace lists", user: "[slug]\nreserved = [\"admin\"]\n", args: []string{"slug", "-r", "x"}, want: "|x|text\n"},
+		{name: "table applies to subcommands", user: "[clip]\nbackend = \"xsel\"\n", args: []string{"clip", "copy"}, want: "xsel\n"},
+		{name: "dotted key", user: "slug.reserved = [\"admin\"]\n", args: []string{"slug"}, want: "|admin|text\n"},
+		{name: "dotted key in a table", user: "[clip]\ncopy.backend = \"xclip\"\n", args: []string{"clip", "copy"}, want: "xclip\n"},
+		{
+			name: "own table over parent",
+			user: "[clip]\nbackend = \"xsel\"\n\n[clip.copy]\nbackend = \"xclip\"\n",
+			args: []string{"clip", "copy"},
+			want: "xclip\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root, dir := newConfigRoot(t)
+			if tc.user != "" {
+				writeFile(t, filepath.Join(dir, "xdg", "tool", "config.toml"), tc.user)
+			}
+			if tc.project != "" {
+				writeFile(t, filepath.Join(dir, "work", ".tool.toml"), tc.project)
+			}
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			code, stdout, stderr := run(t, root, tc.args...)
+			if code != cli.ExitOK {
+				t.Fatalf("Execute(%q) code = %d; stderr = %s", tc.args, code, stderr)
+			}
+			if stdout != tc.want {
+				t.Fatalf("Execute(%q) = %q, want %q", tc.args, stdout, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		args    []string
+		want    int
+		wantErr string
+	}{
+		{name: "syntax", user: "[case\n", args: []string{"case"}, want: cli.ExitConfig, wantErr: "line 1"},
+		{name: "dotted key defined twice", user: "slug.reserved = [\"a\"]\n\n[slug]\nreserved = [\"b\"]\n", args: []string{"slug"}, want: cli.ExitConfig, wantErr: "line 4: slug.reserved defined twice"},
+		{name: "list for single flag", user: "[case]\nstyle = [\"a\", \"b\"]\n", args: []string{"case"}, want: cli.ExitConfig, wantErr: "case.style"},
+		{name: "missing explicit file", args: []string{"--config", "nope.toml", "case"}, want: cli.ExitConfig, wantErr: "nope.toml"},
+		{name: "utilities still run", user: "[case\n", args: []string{"config", "path"}, want: cli.ExitOK},
+		{name: "unknown key", args: []string{"config", "get", "case.styel"}, want: cli.ExitUsage, wantErr: `did you mean "case.style"`},
+		{name: "unset key", args: []string{"config", "get", "slug.reserved"}, want: cli.ExitNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root, dir := newConfigRoot(t)
+			if tc.user != "" {
+				writeFile(t, filepath.Join(dir, "xdg", "tool", "config.toml"), tc.user)
+			}
+
+			code, _, stderr := run(t, root, tc.args...)
+			if code != tc.want {
+				t.Fatalf("Execute(%q) code = %d, want %d; stderr = %s", tc.args, code, tc.want, stderr)
+			}
+			if !strings.Contains(stderr, tc.wantErr) {
+				t.Fatalf("Execute(%q) stderr = %q, want it to mention %q", tc.args, stderr, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigSet(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	user := filepath.Join(dir, "xdg", "tool", "config.toml")
+	writeFile(t, user, "# my settings\n[case] # titles\nstyle = \"apa\"\n")
+
+	for _, args := range [][]string{
+		{"config", "set", "case.style", "chicago"},
+		{"config", "set", "slug.reserved", "admin", "say \"hi\""},
+		{"config", "set", "output", "json"},
+		{"config", "set", "--project", "clip.backend", "xsel"},
+	} {
+		if code, _, stderr := run(t, root, args...); code != cli.ExitOK {
+			t.Fatalf("Execute(%q) code = %d; stderr = %s", args, code, stderr)
+		}
+	}
+
+	b, err := os.ReadFile(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "output = \"json\"\n# my settings\n[case] # titles\nstyle = \"chicago\"\n\n[slug]\nreserved = [\"admin\", \"say \\\"hi\\\"\"]\n"
+	if string(b) != want {
+		t.Fatalf("user config =\n%s\nwant\n%s", b, want)
+	}
+	if _, err := os.Stat(".tool.toml"); err != nil {
+		t.Fatalf("set --project did not create .tool.toml here: %v", err)
+	}
+
+	code, stdout, stderr := run(t, root, "slug", "-o", "text")
+	if code != cli.ExitOK || stdout != "|admin,say \"hi\"|text\n" {
+		t.Fatalf("slug after set = %d %q; stderr = %s", code, stdout, stderr)
+	}
+
+	code, stdout, _ = run(t, root, "config", "get", "clip.backend", "-o", "tsv")
+	if code != cli.ExitOK || !strings.HasPrefix(stdout, "key\tvalues\tsource\nclip.backend\t[\"xsel\"]\t") {
+		t.Fatalf("config get clip.backend = %d %q", code, stdout)
+	}
+
+	if code, _, _ := run(t, root, "config", "set", "case.style", "a", "b"); code != cli.ExitUsage {
+		t.Fatalf("config set with a list for a single flag code = %d, want %d", code, cli.ExitUsage)
+	}
+	if code, _, _ := run(t, root, "config", "set", "config", "x"); code != cli.ExitUsage {
+		t.Fatalf("config set config code = %d, want %d", code, cli.ExitUsage)
+	}
+}
+
+func TestConfigSetDottedKey(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	user := filepath.Join(dir, "xdg", "tool", "config.toml")
+	writeFile(t, user, "slug.reserved = [\"admin\"] # kept in place\n")
+
+	if code, _, stderr := run(t, root, "config", "set", "slug.reserved", "api"); code != cli.ExitOK {
+		t.Fatalf("config set code = %d; stderr = %s", code, stderr)
+	}
+	b, err := os.ReadFile(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "slug.reserved = [\"api\"]\n"; string(b) != want {
+		t.Fatalf("user config = %q, want %q", b, want)
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	user := filepath.Join(dir, "xdg", "tool", "config.toml")
+	project := filepath.Join(dir, "work", ".tool.toml")
+
+	_, stdout, _ := run(t, root, "config", "path")
+	if want := "user:    " + user + " (not found)\n"; stdout != want {
+		t.Fatalf("config path = %q, want %q", stdout, want)
+	}
+
+	writeFile(t, user, "")
+	writeFile(t, project, "")
+	_, stdout, _ = run(t, root, "config", "path")
+	if want := "user:    " + user + "\nproject: " + project + "\n"; stdout != want {
+		t.Fatalf("config path = %q, want %q", stdout, want)
+	}
+}
+
+func TestContextConfigFiles(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	project := filepath.Join(dir, "work", ".tool.toml")
+	writeFile(t, project, "[case]\nstyle = \"mla\"\n")
+
+	var got []string
+	root.Register(cli.New("files", "Show config files").WithRun(func(ctx *cli.Context, args []string) error {
+		got = ctx.ConfigFiles()
+		return nil
+	}))
+	if code, _, stderr := run(t, root, "files"); code != cli.ExitOK {
+		t.Fatalf("files code = %d; stderr = %s", code, stderr)
+	}
+	// The user file does not exist, so only the project file is read.
+	if len(got) != 1 || got[0] != project {
+		t.Fatalf("ConfigFiles() = %q, want [%s]", got, project)
+	}
+}
+
+// removingReader deletes path the first time it is read.
+type removingReader struct {
+	t    *testing.T
+	path string
+	r    io.Reader
+}
+
+func (r *removingReader) Read(p []byte) (int, error) {
+	if r.path != "" {
+		if err := os.Remove(r.path); err != nil {
+			r.t.Error(err)
+		}
+		r.path = ""
+	}
+	return r.r.Read(p)
+}
+
+func TestBatchLoadsConfigOnce(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	project := filepath.Join(dir, "work", ".tool.toml")
+	writeFile(t, project, "[case]\nstyle = \"mla\"\n")
+	root.Register(cli.NewBatchCommand("batch"))
+
+	// The file is gone before the first request runs, so every request
+	// must use the config batch read up front.
+	requests := "{\"cmd\": [\"case\"]}\n{\"cmd\": [\"case\"]}\n"
+	stdin := &removingReader{t: t, path: project, r: strings.NewReader(requests)}
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(root, []string{"batch"}, cli.Options{Stdin: stdin, Stdout: &stdout, Stderr: &stderr})
+	want := strings.Repeat("{\"output\":\"mla||text\",\"exit\":0,\"error\":null}\n", 2)
+	if code != cli.ExitOK || stdout.String() != want {
+		t.Fatalf("batch = %d\n%s\nstderr = %s", code, stdout.String(), stderr.String())
+	}
+}
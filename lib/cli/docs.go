@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenManTree writes a section 1 roff man page for every visible command in the
+// tree, named after the command path (yui-case-kebab.1).
+func GenManTree(root *Command, dir string) error {
+	return walkDocs(root, []string{root.Name}, func(c *Command, path []string) error {
+		name := strings.Join(path, "-") + ".1"
+		return os.WriteFile(filepath.Join(dir, name), []byte(c.ManPage(path)), 0o644)
+	})
+}
+
+// GenMarkdownTree writes a Markdown page for every visible command in the
+// tree, named after the command path (yui_case.md), linked to each other.
+func GenMarkdownTree(root *Command, dir string) error {
+	return walkDocs(root, []string{root.Name}, func(c *Command, path []string) error {
+		return os.WriteFile(filepath.Join(dir, markdownFile(path)), []byte(c.Markdown(path)), 0o644)
+	})
+}
+
+func walkDocs(c *Command, path []string, fn func(*Command, []string) error) error {
+	if err := fn(c, path); err != nil {
+		return err
+	}
+	for _, sub := range c.Subcommands {
+		if sub.Hidden {
+			continue
+		}
+		subPath := append(append([]string{}, path...), sub.Name)
+		if err := walkDocs(sub, subPath, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownFile(path []string) string {
+	return strings.Join(path, "_") + ".md"
+}
+
+func commandLabel(c *Command) string {
+	if len(c.Aliases) == 0 {
+		return c.Name
+	}
+	return c.Name + ", " + strings.Join(c.Aliases, ", ")
+}
+
+// ManPage renders the command as a roff man page.
+func (c *Command) ManPage(path []string) string {
+	cmdPath := strings.Join(path, " ")
+	title := strings.ToUpper(strings.Join(path, "-"))
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".TH %q \"1\" \"\" %q %q\n", title, path[0], path[0]+" manual")
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(strings.Join(path, "-")), roffEscape(c.Description))
+
+	b.WriteString(".SH SYNOPSIS\n.nf\n")
+	for _, line := range c.usageLines(cmdPath) {
+		fmt.Fprintf(&b, "%s\n", roffEscape(line))
+	}
+	b.WriteString(".fi\n")
+
+	b.WriteString(".SH DESCRIPTION\n")
+	fmt.Fprintf(&b, "%s\n", roffEscape(c.Description))
+
+	if subs := visibleSubcommands(c); len(subs) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, sub := range subs {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roffEscape(commandLabel(sub)), roffEscape(sub.Description))
+		}
+		if c.DefaultSubcommand != "" {
+			fmt.Fprintf(&b, ".PP\nDefault: \\fB%s\\fR\n", roffEscape(c.DefaultSubcommand))
+		}
+	}
+
+	writeOptions := func(title string, flags []Flag) {
+		if len(flags) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, ".SH %s\n", title)
+		for _, flag := range flags {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roffEscape(formatFlag(flag)), roffEscape(formatFlagDescription(flag)))
+		}
+	}
+	writeOptions("OPTIONS", c.ownFlags())
+	writeOptions("INHERITED OPTIONS", c.inheritedFlags())
+
+	for _, section := range c.Sections {
+		if len(section.Lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, ".SH %s\n.nf\n", roffEscape(section.Title))
+		for _, line := range section.Lines {
+			fmt.Fprintf(&b, "%s\n", roffEscape(expandPlaceholders(line, cmdPath)))
+		}
+		b.WriteString(".fi\n")
+	}
+
+	if examples := c.exampleLines(cmdPath); len(examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n.nf\n")
+		for _, example := range examples {
+			fmt.Fprintf(&b, "%s\n", roffEscape(example))
+		}
+		b.WriteString(".fi\n")
+	}
+
+	var related []string
+	if len(path) > 1 {
+		related = append(related, strings.Join(path[:len(path)-1], "-"))
+	}
+	for _, sub := range visibleSubcommands(c) {
+		related = append(related, strings.Join(path, "-")+"-"+sub.Name)
+	}
+	if len(related) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		for i, name := range related {
+			sep := ","
+			if i == len(related)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&b, "\\fB%s\\fR(1)%s\n", roffEscape(name), sep)
+		}
+	}
+
+	return b.String()
+}
+
+// Markdown renders the command as a Markdown reference page.
+func (c *Command) Markdown(path []string) string {
+	cmdPath := strings.Join(path, " ")
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n%s\n", cmdPath, c.Description)
+
+	b.WriteString("\n## Usage\n\n```\n")
+	for _, line := range c.usageLines(cmdPath) {
+		fmt.Fprintf(&b, "%s\n", line)
+	}
+	b.WriteString("```\n")
+
+	if subs := visibleSubcommands(c); len(subs) > 0 {
+		b.WriteString("\n## Commands\n\n| Command | Aliases | Description |\n| --- | --- | --- |\n")
+		for _, sub := range subs {
+			subPath := append(append([]string{}, path...), sub.Name)
+			fmt.Fprintf(&b, "| [%s](%s) | %s | %s |\n",
+				sub.Name, markdownFile(subPath), markdownCell(strings.Join(sub.Aliases, ", ")), markdownCell(sub.Description))
+		}
+		if c.DefaultSubcommand != "" {
+			fmt.Fprintf(&b, "\nDefault: `%s`\n", c.DefaultSubcommand)
+		}
+	}
+
+	writeFlags := func(title string, flags []Flag) {
+		if len(flags) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n| Flag | Description |\n| --- | --- |\n", title)
+		for _, flag := range flags {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", formatFlag(flag), markdownCell(formatFlagDescription(flag)))
+		}
+	}
+	writeFlags("Flags", c.ownFlags())
+	writeFlags("Inherited flags", c.inheritedFlags())
+
+	for _, section := range c.Sections {
+		if len(section.Lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n```\n", sectionHeading(section.Title))
+		for _, line := range section.Lines {
+			fmt.Fprintf(&b, "%s\n", expandPlaceholders(line, cmdPath))
+		}
+		b.WriteString("```\n")
+	}
+
+	if examples := c.exampleLines(cmdPath); len(examples) > 0 {
+		b.WriteString("\n## Examples\n\n```sh\n")
+		for _, example := range examples {
+			fmt.Fprintf(&b, "%s\n", example)
+		}
+		b.WriteString("```\n")
+	}
+
+	if len(path) > 1 {
+		parent := path[:len(path)-1]
+		fmt.Fprintf(&b, "\n## See also\n\n- [%s](%s)\n", strings.Join(parent, " "), markdownFile(parent))
+	}
+
+	return b.String()
+}
+
+func visibleSubcommands(c *Command) []*Command {
+	var subs []*Command
+	for _, sub := range c.Subcommands {
+		if !sub.Hidden {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// sectionHeading turns "TITLE CASE STYLES" into "Title case styles".
+func sectionHeading(title string) string {
+	if title == "" {
+		return ""
+	}
+	lower := strings.ToLower(title)
+	return strings.ToUpper(lower[:1]) + lower[1:]
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// NewDocsCommand returns a command that writes the tree's reference docs.
+func NewDocsCommand(name string) *Command {
+	gen := func(format, description string, write func(*Command, string) error) *Command {
+		return New(format, description).
+			WithArgs(RequiredArg("dir")).
+			WithRun(func(ctx *Context, args []string) error {
+				dir := ctx.Arg("dir")
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					return err
+				}
+				if err := write(ctx.Root, dir); err != nil {
+					return err
+				}
+				fmt.Fprintf(ctx.Stderr, "wrote %s docs to %s\n", format, dir)
+				return nil
+			})
+	}
+
+	return New(name, "Generate reference documentation").
+		WithSubcommandName("format").
+		WithExample("Write man pages", "man", "docs/man").
+		WithExample("Write the Markdown reference", "markdown", "docs/reference").
+		Register(
+			gen("man", "Write roff man pages, one per command", GenManTree),
+			gen("markdown", "Write Markdown pages, one per command", GenMarkdownTree).WithAliases("md"),
+		).
+		AsUtility()
+}
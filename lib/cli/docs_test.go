@@ -0,0 +1,92 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func newDocsTree() *cli.Command {
+	return cli.New("tool", "Docs test tool").
+		WithSubcommandName("command").
+		Register(
+			cli.New("slug", "Make slugs").
+				WithAliases("s").
+				WithArgs(cli.VariadicArg("text")).
+				RegisterFlags(cli.StringFlag("reserved", "r", "slug", "Reserve a slug").Repeated()).
+				WithSections(cli.Section{Title: "NOTES", Lines: []string{".dotted line", "use %cmd% | less"}}).
+				WithExamples(`%cmd% "Some Title" -r some-title  # some-title-1`).
+				WithRun(noop),
+			cli.New("secret", "Hidden").Hide().WithRun(noop),
+		)
+}
+
+func TestManPage(t *testing.T) {
+	page := newDocsTree().Subcommands[0].ManPage([]string{"tool", "slug"})
+
+	for _, want := range []string{
+		`.TH "TOOL-SLUG" "1"`,
+		"tool\\-slug \\- Make slugs",
+		".SH SYNOPSIS\n.nf\ntool slug <text...>\n.fi",
+		"\\fB\\-\\-reserved <slug>, \\-r <slug>\\fR\nReserve a slug",
+		".SH NOTES\n.nf\n\\&.dotted line\nuse tool slug | less\n.fi",
+		`tool slug "Some Title" \-r some\-title  # some\-title\-1`,
+		"\\fBtool\\fR(1)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("ManPage() missing %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	root := newDocsTree()
+	page := root.Markdown([]string{"tool"})
+
+	if !strings.Contains(page, "| [slug](tool_slug.md) | s | Make slugs |") {
+		t.Fatalf("Markdown() missing command row:\n%s", page)
+	}
+	if strings.Contains(page, "secret") {
+		t.Fatalf("Markdown() lists hidden command:\n%s", page)
+	}
+
+	sub := root.Subcommands[0].Markdown([]string{"tool", "slug"})
+	for _, want := range []string{
+		"# tool slug",
+		"| `--reserved <slug>, -r <slug>` | Reserve a slug |",
+		"## Notes",
+		"- [tool](tool.md)",
+	} {
+		if !strings.Contains(sub, want) {
+			t.Fatalf("Markdown() missing %q:\n%s", want, sub)
+		}
+	}
+}
+
+func TestGenTrees(t *testing.T) {
+	manDir := t.TempDir()
+	if err := cli.GenManTree(newDocsTree(), manDir); err != nil {
+		t.Fatalf("GenManTree() error = %v", err)
+	}
+	mdDir := t.TempDir()
+	if err := cli.GenMarkdownTree(newDocsTree(), mdDir); err != nil {
+		t.Fatalf("GenMarkdownTree() error = %v", err)
+	}
+
+	for _, file := range []string{
+		filepath.Join(manDir, "tool.1"),
+		filepath.Join(manDir, "tool-slug.1"),
+		filepath.Join(mdDir, "tool.md"),
+		filepath.Join(mdDir, "tool_slug.md"),
+	} {
+		if _, err := os.Stat(file); err != nil {
+			t.Fatalf("expected %s: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(manDir, "tool-secret.1")); err == nil {
+		t.Fatalf("GenManTree() wrote a page for a hidden command")
+	}
+}
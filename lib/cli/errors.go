@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+)
+
+// ErrorKind classifies a failure so Execute can pick an exit code and decide
+// whether help is worth printing.
+type ErrorKind int
+
+const (
+	// KindFailure is a runtime failure with no more specific kind.
+	KindFailure ErrorKind = iota
+	// KindUsage means the command line was wrong; help is printed.
+	KindUsage
+	// KindNotFound means an input file or resource does not exist.
+	KindNotFound
+	// KindUnavailable means a required backend or service is missing.
+	KindUnavailable
+	// KindInterrupted means the command was cancelled, usually by a signal.
+	KindInterrupted
+	// KindConfig means a config file or environment variable is invalid.
+	KindConfig
+)
+
+// Exit codes returned by Execute, following sysexits(3) where one fits.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitUsage       = 64
+	ExitNotFound    = 66
+	ExitUnavailable = 69
+	ExitConfig      = 78
+	ExitInterrupted = 130
+)
+
+// ExitCode returns the process exit code for the kind.
+func (k ErrorKind) ExitCode() int {
+	switch k {
+	case KindUsage:
+		return ExitUsage
+	case KindNotFound:
+		return ExitNotFound
+	case KindUnavailable:
+		return ExitUnavailable
+	case KindInterrupted:
+		return ExitInterrupted
+	case KindConfig:
+		return ExitConfig
+	default:
+		return ExitFailure
+	}
+}
+
+// kindOfExitCode returns the kind whose ExitCode is code.
+func kindOfExitCode(code int) ErrorKind {
+	for _, kind := range []ErrorKind{KindUsage, KindNotFound, KindUnavailable, KindInterrupted, KindConfig} {
+		if kind.ExitCode() == code {
+			return kind
+		}
+	}
+	return KindFailure
+}
+
+// Error attaches an ErrorKind to an error returned from a RunFunc.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError wraps err with kind. A nil err stays nil.
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// Errorf formats an error of the given kind.
+func Errorf(kind ErrorKind, format string, args ...any) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// KindOf classifies err. An explicit *Error wins; otherwise well-known
+// standard library errors are recognized, and anything else is a failure.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	switch {
+	case errors.As(err, &e):
+		return e.Kind
+	case errors.Is(err, context.Canceled):
+		return KindInterrupted
+	case errors.Is(err, fs.ErrNotExist):
+		return KindNotFound
+	case errors.Is(err, exec.ErrNotFound):
+		return KindUnavailable
+	default:
+		return KindFailure
+	}
+}
+
+// ExitCode returns the exit code Execute uses for err.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return KindOf(err).ExitCode()
+}
+
+// ExitCodeSection documents the exit codes for a root command's help.
+func ExitCodeSection() Section {
+	return Section{
+		Title: "EXIT CODES",
+		Lines: []string{
+			"0    Success",
+			"1    Runtime failure",
+			"64   Usage error (bad command, flag or argument)",
+			"66   Input not found",
+			"69   Required backend unavailable",
+			"78   Invalid configuration",
+			"130  Interrupted",
+		},
+	}
+}
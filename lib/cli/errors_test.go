@@ -0,0 +1,96 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: cli.ExitOK},
+		{name: "plain", err: errors.New("boom"), want: cli.ExitFailure},
+		{name: "usage", err: cli.Errorf(cli.KindUsage, "bad"), want: cli.ExitUsage},
+		{name: "wrapped kind", err: fmt.Errorf("outer: %w", cli.NewError(cli.KindUnavailable, errors.New("no backend"))), want: cli.ExitUnavailable},
+		{name: "not exist", err: fmt.Errorf("open: %w", os.ErrNotExist), want: cli.ExitNotFound},
+		{name: "missing executable", err: &exec.Error{Name: "xclip", Err: exec.ErrNotFound}, want: cli.ExitUnavailable},
+		{name: "cancelled", err: context.Canceled, want: cli.ExitInterrupted},
+		{name: "config", err: cli.Errorf(cli.KindConfig, "bad config"), want: cli.ExitConfig},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cli.ExitCode(tc.err); got != tc.want {
+				t.Fatalf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteExitCodes(t *testing.T) {
+	failWith := func(err error) cli.RunFunc {
+		return func(ctx *cli.Context, args []string) error { return err }
+	}
+	root := cli.New("tool", "Exit code test tool").Register(
+		cli.New("ok", "Succeed").WithRun(noop),
+		cli.New("paste", "Fail without a backend").WithRun(failWith(cli.NewError(cli.KindUnavailable, errors.New("no clipboard backend found")))),
+		cli.New("bad", "Reject input").WithRun(failWith(cli.Errorf(cli.KindUsage, "unknown mode: x"))),
+		cli.New("boom", "Fail").WithRun(failWith(errors.New("boom"))),
+	)
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     int
+		wantHelp bool
+	}{
+		{name: "success", args: []string{"ok"}, want: cli.ExitOK},
+		{name: "unknown flag", args: []string{"ok", "--nope"}, want: cli.ExitUsage, wantHelp: true},
+		{name: "unexpected argument", args: []string{"ok", "x"}, want: cli.ExitUsage, wantHelp: true},
+		{name: "missing subcommand", args: nil, want: cli.ExitUsage, wantHelp: true},
+		{name: "usage from run", args: []string{"bad"}, want: cli.ExitUsage, wantHelp: true},
+		{name: "unavailable backend", args: []string{"paste"}, want: cli.ExitUnavailable},
+		{name: "runtime failure", args: []string{"boom"}, want: cli.ExitFailure},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.Execute(root, tc.args, &stdout, &stderr)
+			if code != tc.want {
+				t.Fatalf("Execute(%q) code = %d, want %d; stderr = %s", tc.args, code, tc.want, stderr.String())
+			}
+			if gotHelp := strings.Contains(stderr.String(), "USAGE:"); gotHelp != tc.wantHelp {
+				t.Fatalf("Execute(%q) printed help = %v, want %v:\n%s", tc.args, gotHelp, tc.wantHelp, stderr.String())
+			}
+		})
+	}
+}
+
+func TestExecuteWithCancelledContext(t *testing.T) {
+	cmd := cli.New("tool", "Cancellation test tool").
+		WithRun(func(ctx *cli.Context, args []string) error {
+			<-ctx.Context().Done()
+			return errors.New("signal: killed")
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(cmd, nil, cli.Options{Context: ctx, Stdout: &stdout, Stderr: &stderr})
+	if code != cli.ExitInterrupted {
+		t.Fatalf("ExecuteWith() code = %d, want %d; stderr = %s", code, cli.ExitInterrupted, stderr.String())
+	}
+}
@@ -0,0 +1,42 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestExampleSpecsHelp(t *testing.T) {
+	root := cli.New("tool", "Example test tool").
+		WithArgs(cli.VariadicArg("text").FromStdin()).
+		WithExampleSpecs(
+			cli.Example{Args: []string{`"Hello World"`}, Output: "hello-world"},
+			cli.Example{Stdin: `say "hi"`, Note: "Read stdin"},
+			cli.Example{Args: []string{"x"}, Output: "two\nlines"},
+		).
+		WithRun(noop)
+
+	want := "EXAMPLES:\n" +
+		"    tool \"Hello World\"        # hello-world\n" +
+		"    echo \"say \\\"hi\\\"\" | tool  # Read stdin\n" +
+		"    tool x\n"
+	if help := root.Help([]string{"tool"}); !strings.Contains(help, want) {
+		t.Fatalf("help examples =\n%s\nwant\n%s", help, want)
+	}
+}
+
+func TestExampleArgv(t *testing.T) {
+	args, err := cli.Example{Args: []string{"hex", `"rgb(255, 85, 0)"`}}.Argv()
+	if err != nil || strings.Join(args, "|") != "hex|rgb(255, 85, 0)" {
+		t.Fatalf("Argv() = %q, %v", args, err)
+	}
+
+	root := cli.New("tool", "Example test tool").
+		WithArgs(cli.VariadicArg("text")).
+		WithExampleSpecs(cli.Example{Args: []string{`"open`}}).
+		WithRun(noop)
+	if err := cli.Validate(root); err == nil || !strings.Contains(err.Error(), "unterminated") {
+		t.Fatalf("Validate() = %v, want an unterminated quote error", err)
+	}
+}
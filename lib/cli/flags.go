@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FlagType int
+
+const (
+	FlagBool FlagType = iota + 1
+	FlagString
+	FlagInt
+	FlagFloat
+	FlagDuration
+)
+
+var errHelp = errors.New("help requested")
+
+func BoolFlag(name, short, description string) Flag {
+	return Flag{Name: name, Short: short, Description: description, Type: FlagBool}
+}
+
+func StringFlag(name, short, value, description string) Flag {
+	return Flag{Name: name, Short: short, Value: value, Description: description, Type: FlagString}
+}
+
+func IntFlag(name, short, value, description string) Flag {
+	return Flag{Name: name, Short: short, Value: value, Description: description, Type: FlagInt}
+}
+
+func FloatFlag(name, short, value, description string) Flag {
+	return Flag{Name: name, Short: short, Value: value, Description: description, Type: FlagFloat}
+}
+
+func DurationFlag(name, short, value, description string) Flag {
+	return Flag{Name: name, Short: short, Value: value, Description: description, Type: FlagDuration}
+}
+
+// Repeated marks the flag as accepting multiple values, read with Context.Strings.
+func (f Flag) Repeated() Flag {
+	f.Repeatable = true
+	return f
+}
+
+// WithoutConfig keeps the flag out of config files and the environment.
+func (f Flag) WithoutConfig() Flag {
+	f.NoConfig = true
+	return f
+}
+
+// WithDefault sets the value reported when the flag is not given.
+func (f Flag) WithDefault(value string) Flag {
+	f.Default = value
+	return f
+}
+
+// Kind reports the flag type, inferring string for flags with a value
+// placeholder and bool otherwise.
+func (f Flag) Kind() FlagType {
+	if f.Type != 0 {
+		return f.Type
+	}
+	if f.Value != "" {
+		return FlagString
+	}
+	return FlagBool
+}
+
+func (f Flag) takesValue() bool {
+	return f.Kind() != FlagBool
+}
+
+func (f Flag) placeholder() string {
+	if f.Value != "" || !f.takesValue() {
+		return f.Value
+	}
+	switch f.Kind() {
+	case FlagInt:
+		return "int"
+	case FlagFloat:
+		return "number"
+	case FlagDuration:
+		return "duration"
+	default:
+		return "value"
+	}
+}
+
+func (f Flag) check(value string) error {
+	var err error
+	switch f.Kind() {
+	case FlagBool:
+		_, err = strconv.ParseBool(value)
+	case FlagInt:
+		_, err = strconv.Atoi(value)
+	case FlagFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case FlagDuration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for --%s", value, f.Name)
+	}
+	return nil
+}
+
+// flagSet holds the values given on the command line and, beneath them, the
+// defaults read from config.
+type flagSet struct {
+	flags      []Flag
+	values     map[string][]string
+	configured map[string][]string
+}
+
+func newFlagSet(flags []Flag) *flagSet {
+	return &flagSet{
+		flags:  flags,
+		values: make(map[string][]string),
+	}
+}
+
+func (s *flagSet) lookup(name string) *Flag {
+	for i := range s.flags {
+		if s.flags[i].Name == name {
+			return &s.flags[i]
+		}
+	}
+	return nil
+}
+
+func (s *flagSet) lookupShort(short string) *Flag {
+	for i := range s.flags {
+		if s.flags[i].Short != "" && s.flags[i].Short == short {
+			return &s.flags[i]
+		}
+	}
+	return nil
+}
+
+func (s *flagSet) set(flag *Flag, value string) error {
+	if err := flag.check(value); err != nil {
+		return err
+	}
+	if flag.Repeatable {
+		s.values[flag.Name] = append(s.values[flag.Name], value)
+	} else {
+		s.values[flag.Name] = []string{value}
+	}
+	return nil
+}
+
+func (s *flagSet) get(name string) ([]string, bool) {
+	if values, ok := s.values[name]; ok {
+		return values, true
+	}
+	if values, ok := s.configured[name]; ok {
+		return values, false
+	}
+	if flag := s.lookup(name); flag != nil && flag.Default != "" {
+		return []string{flag.Default}, false
+	}
+	return nil, false
+}
+
+// parse consumes flags from args and returns the remaining positional
+// arguments in order. Flags and positionals may be interleaved; everything
+// after a "--" terminator is positional.
+func (s *flagSet) parse(args []string) ([]string, error) {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return append(positional, args[i+1:]...), nil
+		}
+		if !isFlagToken(args[i]) {
+			positional = append(positional, args[i])
+			continue
+		}
+		n, err := s.parseOne(args[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n - 1
+	}
+	return positional, nil
+}
+
+// parseOne parses the flag at args[0] and reports how many arguments it consumed.
+func (s *flagSet) parseOne(args []string) (int, error) {
+	arg := args[0]
+
+	if strings.HasPrefix(arg, "--") {
+		name, value, hasValue := strings.Cut(arg[2:], "=")
+		flag := s.lookup(name)
+		if flag == nil {
+			if name == "help" {
+				return 1, errHelp
+			}
+			return 0, fmt.Errorf("unknown flag: --%s", name)
+		}
+		if !flag.takesValue() {
+			if !hasValue {
+				value = "true"
+			}
+			return 1, s.set(flag, value)
+		}
+		if hasValue {
+			if value == "" {
+				return 0, fmt.Errorf("--%s requires a value", name)
+			}
+			return 1, s.set(flag, value)
+		}
+		if len(args) < 2 {
+			return 0, fmt.Errorf("--%s requires a value", name)
+		}
+		return 2, s.set(flag, args[1])
+	}
+
+	cluster := arg[1:]
+	for i := 0; i < len(cluster); i++ {
+		short := cluster[i : i+1]
+		flag := s.lookupShort(short)
+		if flag == nil {
+			if short == "h" {
+				return 1, errHelp
+			}
+			return 0, fmt.Errorf("unknown flag: -%s", short)
+		}
+		if !flag.takesValue() {
+			if err := s.set(flag, "true"); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		value := strings.TrimPrefix(cluster[i+1:], "=")
+		if value != "" {
+			return 1, s.set(flag, value)
+		}
+		if len(args) < 2 {
+			return 0, fmt.Errorf("-%s requires a value", short)
+		}
+		return 2, s.set(flag, args[1])
+	}
+	return 1, nil
+}
+
+// isFlagToken reports whether arg looks like a flag. A lone "-" and negative
+// numbers are treated as positional values.
+func isFlagToken(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return false
+	}
+	return true
+}
+
+func (c *Context) IsSet(name string) bool {
+	if c.flags == nil {
+		return false
+	}
+	_, ok := c.flags.values[name]
+	return ok
+}
+
+func (c *Context) value(name string) string {
+	if c.flags == nil {
+		return ""
+	}
+	values, _ := c.flags.get(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+func (c *Context) Bool(name string) bool {
+	v, _ := strconv.ParseBool(c.value(name))
+	return v
+}
+
+func (c *Context) String(name string) string {
+	return c.value(name)
+}
+
+func (c *Context) Int(name string) int {
+	v, _ := strconv.Atoi(c.value(name))
+	return v
+}
+
+func (c *Context) Float(name string) float64 {
+	v, _ := strconv.ParseFloat(c.value(name), 64)
+	return v
+}
+
+func (c *Context) Duration(name string) time.Duration {
+	v, _ := time.ParseDuration(c.value(name))
+	return v
+}
+
+// Strings returns every value given for a repeatable flag, in order.
+func (c *Context) Strings(name string) []string {
+	if c.flags == nil {
+		return nil
+	}
+	values, _ := c.flags.get(name)
+	return append([]string(nil), values...)
+}
@@ -0,0 +1,152 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func newFlagCommand(out *string) *cli.Command {
+	return cli.New("tool", "Flag test tool").
+		WithArgs(cli.Arg{Name: "arg", Optional: true, Variadic: true}).
+		RegisterFlags(
+			cli.BoolFlag("verbose", "v", "Verbose output"),
+			cli.BoolFlag("all", "a", "Everything"),
+			cli.StringFlag("name", "n", "name", "Name to use").WithDefault("anon"),
+			cli.IntFlag("count", "c", "", "Count"),
+			cli.FloatFlag("ratio", "", "", "Ratio"),
+			cli.DurationFlag("wait", "w", "", "Wait"),
+			cli.StringFlag("tag", "t", "tag", "Tag; repeatable").Repeated(),
+		).
+		WithRun(func(ctx *cli.Context, args []string) error {
+			*out = fmt.Sprintf("verbose=%v all=%v name=%s count=%d ratio=%v wait=%v tags=%v args=%v",
+				ctx.Bool("verbose"),
+				ctx.Bool("all"),
+				ctx.String("name"),
+				ctx.Int("count"),
+				ctx.Float("ratio"),
+				ctx.Duration("wait"),
+				ctx.Strings("tag"),
+				args,
+			)
+			return nil
+		})
+}
+
+func TestExecuteParsesFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "defaults",
+			args: []string{"x"},
+			want: "verbose=false all=false name=anon count=0 ratio=0 wait=0s tags=[] args=[x]",
+		},
+		{
+			name: "long flags with separate and inline values",
+			args: []string{"--name", "bob", "--count=3", "--ratio=0.5", "--wait", "2s", "x"},
+			want: "verbose=false all=false name=bob count=3 ratio=0.5 wait=2s tags=[] args=[x]",
+		},
+		{
+			name: "combined short flags",
+			args: []string{"-va", "x"},
+			want: "verbose=true all=true name=anon count=0 ratio=0 wait=0s tags=[] args=[x]",
+		},
+		{
+			name: "combined short flags ending with value",
+			args: []string{"-vc", "4", "-n=al"},
+			want: "verbose=true all=false name=al count=4 ratio=0 wait=0s tags=[] args=[]",
+		},
+		{
+			name: "attached short value",
+			args: []string{"-c7"},
+			want: "verbose=false all=false name=anon count=7 ratio=0 wait=0s tags=[] args=[]",
+		},
+		{
+			name: "repeatable values keep order",
+			args: []string{"-t", "a", "x", "--tag=b", "-tc"},
+			want: "verbose=false all=false name=anon count=0 ratio=0 wait=0s tags=[a b c] args=[x]",
+		},
+		{
+			name: "explicit bool value",
+			args: []string{"--verbose=false", "--all=true"},
+			want: "verbose=false all=true name=anon count=0 ratio=0 wait=0s tags=[] args=[]",
+		},
+		{
+			name: "terminator",
+			args: []string{"-v", "--", "--name", "-a"},
+			want: "verbose=true all=false name=anon count=0 ratio=0 wait=0s tags=[] args=[--name -a]",
+		},
+		{
+			name: "dash and negative numbers are positional",
+			args: []string{"-", "-5", "-0.25"},
+			want: "verbose=false all=false name=anon count=0 ratio=0 wait=0s tags=[] args=[- -5 -0.25]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			var stdout, stderr bytes.Buffer
+			code := cli.Execute(newFlagCommand(&got), tc.args, &stdout, &stderr)
+			if code != 0 {
+				t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+			}
+			if got != tc.want {
+				t.Fatalf("Execute() got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsBadFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "unknown long", args: []string{"--nope"}, want: "unknown flag: --nope"},
+		{name: "unknown short", args: []string{"-vz"}, want: "unknown flag: -z"},
+		{name: "missing value", args: []string{"--name"}, want: "--name requires a value"},
+		{name: "empty inline value", args: []string{"--name="}, want: "--name requires a value"},
+		{name: "bad int", args: []string{"--count", "many"}, want: `invalid value "many" for --count`},
+		{name: "bad duration", args: []string{"-w", "soon"}, want: `invalid value "soon" for --wait`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			var stdout, stderr bytes.Buffer
+			code := cli.Execute(newFlagCommand(&got), tc.args, &stdout, &stderr)
+			if code == 0 {
+				t.Fatalf("Execute() code = 0, want failure")
+			}
+			if !strings.Contains(stderr.String(), tc.want) {
+				t.Fatalf("Execute() stderr = %q, want %q", stderr.String(), tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteHelpFlagAfterArgs(t *testing.T) {
+	var got string
+	var stdout, stderr bytes.Buffer
+	code := cli.Execute(newFlagCommand(&got), []string{"x", "--help"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Execute() code = %d, want 0", code)
+	}
+	if got != "" {
+		t.Fatalf("Execute() ran command on --help")
+	}
+	if !strings.Contains(stdout.String(), "Name to use (default: anon)") {
+		t.Fatalf("Help() missing flag default:\n%s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "--count <int>, -c <int>") {
+		t.Fatalf("Help() missing typed placeholder:\n%s", stdout.String())
+	}
+}
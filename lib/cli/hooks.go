@@ -0,0 +1,66 @@
+package cli
+
+import "context"
+
+// PreRunFunc runs before a command's Run. Returning an error skips Run.
+type PreRunFunc func(ctx *Context) error
+
+// PostRunFunc runs after a command's Run, or after a failed PreRun, with
+// the error so far. It returns the error to report, usually err unchanged.
+type PostRunFunc func(ctx *Context, err error) error
+
+// WithPreRun adds hooks that run before this command and every command
+// below it, parents first.
+func (c *Command) WithPreRun(hooks ...PreRunFunc) *Command {
+	c.PreRun = append(c.PreRun, hooks...)
+	return c
+}
+
+// WithPostRun adds hooks that run after this command and every command
+// below it, children first, even when the command fails.
+func (c *Command) WithPostRun(hooks ...PostRunFunc) *Command {
+	c.PostRun = append(c.PostRun, hooks...)
+	return c
+}
+
+// SetContext replaces the command's context.Context, so a PreRun hook can
+// attach values for Run and the PostRun hooks.
+func (c *Context) SetContext(ctx context.Context) {
+	c.context = ctx
+}
+
+// runWithHooks runs cmd between the PreRun hooks of the root down to cmd and
+// the PostRun hooks back up. A level's PostRun hooks run, last added first,
+// whenever its PreRun hooks started, so a hook can rely on cleaning up after
+// itself.
+func runWithHooks(cmd *Command, ctx *Context, args []string) error {
+	var chain []*Command
+	for c := cmd; c != nil; c = c.parent {
+		chain = append([]*Command{c}, chain...)
+	}
+
+	var err error
+	started := 0
+	for _, c := range chain {
+		started++
+		for _, hook := range c.PreRun {
+			if err = hook(ctx); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = cmd.Run(ctx, args)
+	}
+
+	for i := started - 1; i >= 0; i-- {
+		hooks := chain[i].PostRun
+		for j := len(hooks) - 1; j >= 0; j-- {
+			err = hooks[j](ctx, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package cli_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		preErr  error
+		runErr  error
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "order",
+			want: []string{"pre tool", "pre clip", "pre copy", "run", "post copy", "post clip", "post tool"},
+		},
+		{
+			name:    "run error reaches every post hook",
+			runErr:  errors.New("boom"),
+			want:    []string{"pre tool", "pre clip", "pre copy", "run", "post copy boom", "post clip boom", "post tool boom"},
+			wantErr: true,
+		},
+		{
+			name:    "pre error skips run and deeper hooks",
+			preErr:  errors.New("denied"),
+			want:    []string{"pre tool", "pre clip", "post clip denied", "post tool denied"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			pre := func(name string, err error) cli.PreRunFunc {
+				return func(ctx *cli.Context) error {
+					got = append(got, "pre "+name)
+					return err
+				}
+			}
+			post := func(name string) cli.PostRunFunc {
+				return func(ctx *cli.Context, err error) error {
+					if err != nil {
+						got = append(got, "post "+name+" "+err.Error())
+					} else {
+						got = append(got, "post "+name)
+					}
+					return err
+				}
+			}
+
+			root := cli.New("tool", "Hook test tool").
+				WithPreRun(pre("tool", nil)).
+				WithPostRun(post("tool")).
+				Register(
+					cli.New("clip", "Clipboard").
+						WithPreRun(pre("clip", tc.preErr)).
+						WithPostRun(post("clip")).
+						Register(
+							cli.New("copy", "Copy").
+								WithPreRun(pre("copy", nil)).
+								WithPostRun(post("copy")).
+								WithRun(func(ctx *cli.Context, args []string) error {
+									got = append(got, "run")
+									return tc.runErr
+								}),
+						),
+				)
+
+			code, _, stderr := run(t, root, "clip", "copy")
+			if (code != cli.ExitOK) != tc.wantErr {
+				t.Fatalf("Execute code = %d, wantErr %v; stderr = %s", code, tc.wantErr, stderr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("hooks ran %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPostRunReplacesError(t *testing.T) {
+	root := cli.New("tool", "Hook test tool").
+		WithPostRun(func(ctx *cli.Context, err error) error {
+			if err != nil {
+				return cli.Errorf(cli.KindNotFound, "wrapped: %v", err)
+			}
+			return nil
+		}).
+		Register(cli.New("fail", "Fail").WithRun(func(ctx *cli.Context, args []string) error {
+			return errors.New("boom")
+		}))
+
+	if code, _, stderr := run(t, root, "fail"); code != cli.ExitNotFound || stderr != "Error: wrapped: boom\n" {
+		t.Fatalf("Execute(fail) = %d; stderr = %q", code, stderr)
+	}
+}
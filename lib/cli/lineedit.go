@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errLineCancelled is returned by readLine when Ctrl-C discards the line.
+var errLineCancelled = errors.New("line cancelled")
+
+// lineEditor reads lines from a terminal in raw mode, with emacs-style
+// editing keys, history and tab completion. It assumes every rune is one
+// column wide.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	prompt  string
+	history []string
+
+	// complete returns the start of the word being completed and the
+	// replacements offered for line[start:pos].
+	complete func(line []rune, pos int) (int, []string)
+
+	line    []rune
+	pos     int
+	browse  int    // history index being shown, len(history) for the new line
+	pending []rune // the new line, kept while browsing history
+}
+
+// readLine shows the prompt and returns the edited line. It returns io.EOF
+// on Ctrl-D at an empty line and errLineCancelled on Ctrl-C.
+func (e *lineEditor) readLine() (string, error) {
+	e.line, e.pos = nil, 0
+	e.browse, e.pending = len(e.history), nil
+	e.redraw()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.line), nil
+		case 0x03: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errLineCancelled
+		case 0x04: // Ctrl-D
+			if len(e.line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case 0x7f, 0x08: // Backspace
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case 0x01: // Ctrl-A
+			e.pos = 0
+		case 0x05: // Ctrl-E
+			e.pos = len(e.line)
+		case 0x02: // Ctrl-B
+			e.pos = max(e.pos-1, 0)
+		case 0x06: // Ctrl-F
+			e.pos = min(e.pos+1, len(e.line))
+		case 0x0b: // Ctrl-K
+			e.line = e.line[:e.pos]
+		case 0x15: // Ctrl-U
+			e.line = append([]rune{}, e.line[e.pos:]...)
+			e.pos = 0
+		case 0x17: // Ctrl-W
+			start := e.pos
+			for start > 0 && unicode.IsSpace(e.line[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(e.line[start-1]) {
+				start--
+			}
+			e.line = append(e.line[:start], e.line[e.pos:]...)
+			e.pos = start
+		case 0x0c: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 0x10: // Ctrl-P
+			e.walkHistory(-1)
+		case 0x0e: // Ctrl-N
+			e.walkHistory(1)
+		case '\t':
+			e.completeWord()
+		case 0x1b:
+			e.escape()
+		default:
+			if unicode.IsPrint(r) {
+				e.line = append(e.line[:e.pos], append([]rune{r}, e.line[e.pos:]...)...)
+				e.pos++
+			}
+		}
+		e.redraw()
+	}
+}
+
+// escape handles the arrow, Home, End and Delete key sequences.
+func (e *lineEditor) escape() {
+	next, _, err := e.in.ReadRune()
+	if err != nil || (next != '[' && next != 'O') {
+		return
+	}
+	key, _, err := e.in.ReadRune()
+	if err != nil {
+		return
+	}
+	switch key {
+	case 'A':
+		e.walkHistory(-1)
+	case 'B':
+		e.walkHistory(1)
+	case 'C':
+		e.pos = min(e.pos+1, len(e.line))
+	case 'D':
+		e.pos = max(e.pos-1, 0)
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.line)
+	case '3':
+		if tilde, _, err := e.in.ReadRune(); err == nil && tilde == '~' {
+			e.deleteAt(e.pos)
+		}
+	}
+}
+
+func (e *lineEditor) deleteAt(i int) {
+	if i < len(e.line) {
+		e.line = append(e.line[:i], e.line[i+1:]...)
+	}
+}
+
+// walkHistory moves through the history by step, keeping the line being
+// typed so walking back down restores it.
+func (e *lineEditor) walkHistory(step int) {
+	next := e.browse + step
+	if next < 0 || next > len(e.history) {
+		return
+	}
+	if e.browse == len(e.history) {
+		e.pending = append([]rune{}, e.line...)
+	}
+	e.browse = next
+	if next == len(e.history) {
+		e.line = append([]rune{}, e.pending...)
+	} else {
+		e.line = []rune(e.history[next])
+	}
+	e.pos = len(e.line)
+}
+
+// completeWord replaces the word before the cursor with its only
+// completion, or with the longest prefix its completions share, listing
+// them when that adds nothing.
+func (e *lineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	start, candidates := e.complete(e.line, e.pos)
+	if len(candidates) == 0 {
+		return
+	}
+
+	word := string(e.line[start:e.pos])
+	replacement := candidates[0]
+	if len(candidates) == 1 {
+		if !strings.HasSuffix(replacement, "/") {
+			replacement += " "
+		}
+	} else {
+		for _, c := range candidates[1:] {
+			for !strings.HasPrefix(c, replacement) {
+				_, size := utf8.DecodeLastRuneInString(replacement)
+				replacement = replacement[:len(replacement)-size]
+			}
+		}
+		if len(replacement) <= len(word) {
+			fmt.Fprint(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+			return
+		}
+	}
+
+	rest := append([]rune(replacement), e.line[e.pos:]...)
+	e.line = append(e.line[:start:start], rest...)
+	e.pos = start + len([]rune(replacement))
+}
+
+// redraw rewrites the prompt and line, then puts the cursor back.
+func (e *lineEditor) redraw() {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", e.prompt, string(e.line))
+	if back := len(e.line) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
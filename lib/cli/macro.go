@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Macro is a user-defined command name that expands to an argument list
+// before subcommand lookup, so "title" can stand for "case chicago".
+type Macro struct {
+	Name string
+	Args []string
+}
+
+// WithMacros adds macros to the command. Roots built WithConfig also load
+// them from the config's [alias] table.
+func (c *Command) WithMacros(macros ...Macro) *Command {
+	c.Macros = append(c.Macros, macros...)
+	return c
+}
+
+// macros returns the command's own macros followed, on a root, by those a
+// run loaded from config.
+func (c *Command) macros(config []Macro) []Macro {
+	if c.parent != nil {
+		config = nil
+	}
+	return append(append([]Macro{}, c.Macros...), config...)
+}
+
+func (c *Command) findMacro(token string, config []Macro) (Macro, bool) {
+	for _, macro := range c.macros(config) {
+		if strings.EqualFold(macro.Name, token) {
+			return macro, true
+		}
+	}
+	return Macro{}, false
+}
+
+// aliasTable is the config table macros are read from.
+const aliasTable = "alias"
+
+// loadMacros reads the [alias] tables of every config file, letting the
+// project file override the user file.
+func loadMacros(cfg *config) ([]Macro, error) {
+	var macros []Macro
+	seen := make(map[string]bool)
+	for _, layer := range cfg.layers {
+		if layer.doc == nil {
+			continue
+		}
+		names, values := layer.doc.entries(aliasTable)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			args, err := macroArgs(values[name])
+			if err != nil {
+				return nil, Errorf(KindConfig, "%s: %s.%s: %v", layer.path, aliasTable, name, err)
+			}
+			macros = append(macros, Macro{Name: name, Args: args})
+		}
+	}
+	return macros, nil
+}
+
+// macroArgs reads an alias value: a string split like a shell command line,
+// or a list taken word for word.
+func macroArgs(values []string) ([]string, error) {
+	args := values
+	if len(values) == 1 {
+		var err error
+		if args, err = SplitArgs(values[0]); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) == 0 {
+		return nil, errors.New("expands to nothing")
+	}
+	return args, nil
+}
+
+// validateMacros reports macros that a subcommand or help would shadow,
+// and macros that could never run.
+func validateMacros(c *Command, macros []Macro, cmdPath string, errs *[]string) {
+	seen := make(map[string]bool)
+	for _, macro := range macros {
+		key := strings.ToLower(macro.Name)
+		switch {
+		case macro.Name == "" || strings.ContainsAny(macro.Name, " \t.") || isFlagToken(macro.Name):
+			*errs = append(*errs, fmt.Sprintf("command %q has invalid alias name %q", cmdPath, macro.Name))
+		case c.findSubcommand(macro.Name) != nil:
+			*errs = append(*errs, fmt.Sprintf("command %q alias %q collides with built-in command %q", cmdPath, macro.Name, c.findSubcommand(macro.Name).Name))
+		case isHelpArg(macro.Name):
+			*errs = append(*errs, fmt.Sprintf("command %q alias %q is shadowed by built-in help", cmdPath, macro.Name))
+		case seen[key]:
+			*errs = append(*errs, fmt.Sprintf("command %q has duplicate alias %q", cmdPath, macro.Name))
+		}
+		seen[key] = true
+
+		if len(macro.Args) == 0 {
+			*errs = append(*errs, fmt.Sprintf("command %q alias %q expands to nothing", cmdPath, macro.Name))
+		} else if strings.EqualFold(macro.Args[0], macro.Name) {
+			*errs = append(*errs, fmt.Sprintf("command %q alias %q expands to itself", cmdPath, macro.Name))
+		}
+	}
+}
+
+// loadConfigMacros reads root's macros from the [alias] tables, checking
+// them as Validate would. Macros are loaded for each run rather than kept
+// on the tree, which several runs may share at once.
+func loadConfigMacros(root *Command, cfg *config) ([]Macro, error) {
+	macros, err := loadMacros(cfg)
+	if err != nil {
+		return macros, err
+	}
+	var errs []string
+	validateMacros(root, root.macros(macros), root.Name, &errs)
+	if len(errs) > 0 {
+		return macros, Errorf(KindConfig, "%s", strings.Join(errs, "\n"))
+	}
+	return macros, nil
+}
+
+// checkMacro reports why macro cannot be added to root's config.
+func checkMacro(root *Command, macro Macro) error {
+	var errs []string
+	validateMacros(root, []Macro{macro}, root.Name, &errs)
+	if len(errs) > 0 {
+		return Errorf(KindUsage, "%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// SplitArgs splits a command line into words the way a POSIX shell would,
+// honoring single and double quotes and backslash escapes, without any
+// expansion.
+func SplitArgs(s string) ([]string, error) {
+	return splitArgs(s, nil)
+}
+
+// splitArgs is SplitArgs expanding $name to vars[name] outside single
+// quotes. Unknown names are left as written.
+func splitArgs(s string, vars map[string]string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) && r != '\n' {
+				word.WriteRune('\\')
+			}
+			if r != '\n' {
+				word.WriteRune(r)
+			}
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '$' && vars != nil:
+			j := i + 1
+			for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			if value, ok := vars[string(runes[i+1:j])]; ok {
+				word.WriteString(value)
+				i = j - 1
+			} else {
+				word.WriteRune(r)
+			}
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// JoinArgs quotes args so SplitArgs returns them unchanged.
+func JoinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func quoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(arg, " \t\n'\"\\$`|&;<>()*?[]#~{}!") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// configFlag finds a --config value among args without parsing them, so
+// macros can be loaded before the command line is walked.
+func configFlag(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			return value
+		}
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package cli_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "case chicago", want: []string{"case", "chicago"}},
+		{in: "  case\t kebab  ", want: []string{"case", "kebab"}},
+		{in: `slug "Hello World"`, want: []string{"slug", "Hello World"}},
+		{in: `echo 'it''s' ""`, want: []string{"echo", "its", ""}},
+		{in: `a\ b "c\"d" 'e\f' "g\h"`, want: []string{"a b", `c"d`, `e\f`, `g\h`}},
+	}
+
+	for _, tc := range tests {
+		got, err := cli.SplitArgs(tc.in)
+		if err != nil {
+			t.Fatalf("SplitArgs(%q) error = %v", tc.in, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("SplitArgs(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		if tc.want != nil {
+			round, err := cli.SplitArgs(cli.JoinArgs(tc.want))
+			if err != nil || !reflect.DeepEqual(round, tc.want) {
+				t.Fatalf("SplitArgs(JoinArgs(%q)) = %q, %v", tc.want, round, err)
+			}
+		}
+	}
+
+	for _, in := range []string{`"open`, `'open`, `trailing\`} {
+		if _, err := cli.SplitArgs(in); err == nil {
+			t.Fatalf("SplitArgs(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestExecuteMacros(t *testing.T) {
+	echo := func(ctx *cli.Context, args []string) error {
+		return ctx.Render(strings.Join(ctx.Path, " ") + ": " + strings.Join(args, ","))
+	}
+	root := cli.New("tool", "Macro test tool").
+		WithMacros(
+			cli.Macro{Name: "title", Args: []string{"case", "chicago"}},
+			cli.Macro{Name: "t", Args: []string{"title"}},
+		).
+		Register(cli.New("case", "Convert case").WithArgs(cli.VariadicArg("words")).WithRun(echo))
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"title", "a b"}, want: "tool case: chicago,a b\n"},
+		{args: []string{"t", "x"}, want: "tool case: chicago,x\n"},
+		{args: []string{"case", "title"}, want: "tool case: title\n"},
+	}
+
+	for _, tc := range tests {
+		code, stdout, stderr := run(t, root, tc.args...)
+		if code != cli.ExitOK || stdout != tc.want {
+			t.Fatalf("Execute(%q) = %d %q, want %q; stderr = %s", tc.args, code, stdout, tc.want, stderr)
+		}
+	}
+
+	if help := root.Help([]string{"tool"}); !strings.Contains(help, "ALIASES:\n    title  case chicago\n    t      title\n") {
+		t.Fatalf("help does not list aliases:\n%s", help)
+	}
+}
+
+func TestValidateMacros(t *testing.T) {
+	tests := []struct {
+		name  string
+		macro cli.Macro
+		want  string
+	}{
+		{name: "builtin", macro: cli.Macro{Name: "CASE", Args: []string{"x"}}, want: `collides with built-in command "case"`},
+		{name: "builtin alias", macro: cli.Macro{Name: "c", Args: []string{"x"}}, want: `collides with built-in command "case"`},
+		{name: "help", macro: cli.Macro{Name: "help", Args: []string{"case"}}, want: "shadowed by built-in help"},
+		{name: "empty", macro: cli.Macro{Name: "nothing"}, want: "expands to nothing"},
+		{name: "self", macro: cli.Macro{Name: "loop", Args: []string{"loop"}}, want: "expands to itself"},
+		{name: "flag name", macro: cli.Macro{Name: "--x", Args: []string{"case"}}, want: "invalid alias name"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := cli.New("tool", "Macro test tool").
+				WithMacros(tc.macro).
+				Register(cli.New("case", "Convert case").WithAliases("c").WithRun(noop))
+			err := cli.Validate(root)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigMacros(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	user := filepath.Join(dir, "xdg", "tool", "config.toml")
+	writeFile(t, user, "[alias]\nchicago = \"case --style chicago\"\nreserve = [\"slug\", \"-r\", \"admin\"]\n")
+	writeFile(t, filepath.Join(dir, "work", ".tool.toml"), "[alias]\nchicago = \"case --style 'mla'\"\n")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"chicago"}, want: "mla||text\n"},
+		{args: []string{"-o", "tsv", "reserve"}, want: "|admin|tsv\n"},
+	}
+	for _, tc := range tests {
+		code, stdout, stderr := run(t, root, tc.args...)
+		if code != cli.ExitOK || stdout != tc.want {
+			t.Fatalf("Execute(%q) = %d %q, want %q; stderr = %s", tc.args, code, stdout, tc.want, stderr)
+		}
+	}
+
+	if code, _, stderr := run(t, root, "config", "set", "alias.slug", "case"); code != cli.ExitUsage || !strings.Contains(stderr, "collides") {
+		t.Fatalf("config set alias.slug = %d; stderr = %s", code, stderr)
+	}
+
+	writeFile(t, user, "[alias]\nslug = \"case\"\n")
+	if code, _, stderr := run(t, root, "chicago"); code != cli.ExitConfig || !strings.Contains(stderr, "collides") {
+		t.Fatalf("Execute with a colliding config alias = %d; stderr = %s", code, stderr)
+	}
+	if code, stdout, stderr := run(t, root, "slug", "-r", "x"); code != cli.ExitOK || stdout != "|x|text\n" {
+		t.Fatalf("builtin with a colliding config alias = %d %q; stderr = %s", code, stdout, stderr)
+	}
+}
+
+func TestConfigMacrosPerRun(t *testing.T) {
+	root, dir := newConfigRoot(t)
+	styles := []string{"chicago", "mla"}
+	for _, style := range styles {
+		writeFile(t, filepath.Join(dir, style+".toml"), "[alias]\nt = \"case --style "+style+"\"\n")
+	}
+
+	// Runs sharing the tree must each see the aliases from their own config.
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			style := styles[i%len(styles)]
+			code, stdout, stderr := run(t, root, "--config", filepath.Join(dir, style+".toml"), "t")
+			if want := style + "||text\n"; code != cli.ExitOK || stdout != want {
+				errs <- fmt.Errorf("run %d = %d %q, want %q; stderr = %s", i, code, stdout, want, stderr)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
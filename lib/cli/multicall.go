@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithMulticall makes a root command dispatch on the name it was invoked as:
+// run through a link named after one of its subcommands, it behaves as that
+// subcommand, busybox style.
+func (c *Command) WithMulticall() *Command {
+	c.Multicall = true
+	return c
+}
+
+// AsUtility marks a command as part of the tool's plumbing (completion, docs,
+// version) rather than a tool of its own. Utilities get no multicall links.
+func (c *Command) AsUtility() *Command {
+	c.Utility = true
+	return c
+}
+
+// isUtility reports whether c or one of its ancestors is a utility.
+func (c *Command) isUtility() bool {
+	for p := c; p != nil; p = p.parent {
+		if p.Utility {
+			return true
+		}
+	}
+	return false
+}
+
+func programName(program string) string {
+	return strings.TrimSuffix(filepath.Base(program), ".exe")
+}
+
+// multicallTarget returns the subcommand named by program, if any.
+func (c *Command) multicallTarget(program string) *Command {
+	if !c.Multicall || program == "" {
+		return nil
+	}
+	name := programName(program)
+	if strings.EqualFold(name, c.Name) {
+		return nil
+	}
+	sub := c.findSubcommand(name)
+	if sub == nil || sub.Hidden {
+		return nil
+	}
+	return sub
+}
+
+// linkNames returns the names install-links creates for the tree: every
+// visible, non-utility top-level command, optionally with its aliases.
+func (c *Command) linkNames(aliases bool) []string {
+	var names []string
+	for _, sub := range c.Subcommands {
+		if sub.Hidden || sub.Utility {
+			continue
+		}
+		names = append(names, sub.Name)
+		if aliases {
+			names = append(names, sub.Aliases...)
+		}
+	}
+	return names
+}
+
+type link struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Target string `json:"target"`
+}
+
+type links []link
+
+func (l links) Text() string {
+	lines := make([]string, len(l))
+	for i, link := range l {
+		lines[i] = link.Path + " -> " + link.Target
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewInstallLinksCommand returns a command that symlinks the running binary
+// under each tool name so a multicall root can be deployed as one file.
+func NewInstallLinksCommand(name string) *Command {
+	return New(name, "Link tool names to this binary for multicall use").
+		WithArgs(RequiredArg("dir")).
+		RegisterFlags(
+			BoolFlag("aliases", "a", "Also link each command's aliases"),
+			BoolFlag("force", "f", "Replace existing files"),
+		).
+		WithExample("Link every tool into ~/.local/bin", "~/.local/bin").
+		WithExample("Include aliases, replacing old links", "--aliases", "--force", "/usr/local/bin").
+		WithRun(func(ctx *Context, args []string) error {
+			exe, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+				exe = resolved
+			}
+
+			dir := ctx.Arg("dir")
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+
+			ext := ""
+			if strings.HasSuffix(exe, ".exe") {
+				ext = ".exe"
+			}
+
+			var made links
+			for _, name := range ctx.Root.linkNames(ctx.Bool("aliases")) {
+				path := filepath.Join(dir, name+ext)
+				if ctx.Bool("force") {
+					if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+						return err
+					}
+				}
+				if err := os.Symlink(exe, path); err != nil {
+					if errors.Is(err, fs.ErrExist) {
+						return fmt.Errorf("%s already exists; use --force to replace it", path)
+					}
+					return err
+				}
+				made = append(made, link{Name: name, Path: path, Target: exe})
+			}
+			if len(made) == 0 {
+				return fmt.Errorf("%s has no tools to link", ctx.Root.Name)
+			}
+			return ctx.Render(made)
+		}).
+		AsUtility()
+}
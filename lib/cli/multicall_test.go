@@ -0,0 +1,104 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func newMulticallRoot() *cli.Command {
+	echo := func(ctx *cli.Context, args []string) error {
+		return ctx.Render(ctx.Command.Name + ":" + strings.Join(args, ","))
+	}
+	return cli.New("tool", "Multicall test tool").
+		WithMulticall().
+		RegisterPersistentFlags(cli.OutputFlag()).
+		Register(
+			cli.New("slug", "Slug text").WithAliases("s").WithArgs(cli.VariadicArg("text")).WithRun(echo),
+			cli.New("hidden", "Hidden tool").WithRun(echo).Hide(),
+			cli.New("version", "Show version").WithRun(echo).AsUtility(),
+		)
+}
+
+func TestExecuteMulticall(t *testing.T) {
+	tests := []struct {
+		name    string
+		program string
+		args    []string
+		want    string
+	}{
+		{name: "link name", program: "/usr/local/bin/slug", args: []string{"a", "b"}, want: "slug:a,b\n"},
+		{name: "alias link", program: "s", args: []string{"a"}, want: "slug:a\n"},
+		{name: "windows link", program: `slug.exe`, args: []string{"a"}, want: "slug:a\n"},
+		{name: "root persistent flag", program: "slug", args: []string{"-o", "json", "a"}, want: "\"slug:a\"\n"},
+		{name: "root name", program: "/usr/bin/tool", args: []string{"slug", "a"}, want: "slug:a\n"},
+		{name: "unknown name", program: "other", args: []string{"slug", "a"}, want: "slug:a\n"},
+		{name: "hidden", program: "hidden", args: []string{"slug", "a"}, want: "slug:a\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.ExecuteWith(newMulticallRoot(), tc.args, cli.Options{Program: tc.program, Stdout: &stdout, Stderr: &stderr})
+			if code != cli.ExitOK {
+				t.Fatalf("ExecuteWith(%q) as %q code = %d; stderr = %s", tc.args, tc.program, code, stderr.String())
+			}
+			if got := stdout.String(); got != tc.want {
+				t.Fatalf("ExecuteWith(%q) as %q = %q, want %q", tc.args, tc.program, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteMulticallDisabled(t *testing.T) {
+	root := cli.New("tool", "Multicall test tool").Register(cli.New("slug", "Slug text").WithRun(noop))
+
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(root, nil, cli.Options{Program: "slug", Stdout: &stdout, Stderr: &stderr})
+	if code != cli.ExitUsage {
+		t.Fatalf("ExecuteWith() code = %d, want %d; stderr = %s", code, cli.ExitUsage, stderr.String())
+	}
+}
+
+func TestInstallLinks(t *testing.T) {
+	dir := t.TempDir()
+	root := newMulticallRoot().Register(cli.NewInstallLinksCommand("install-links"))
+
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := cli.ExecuteWith(root, append([]string{"install-links"}, args...), cli.Options{Stdout: &stdout, Stderr: &stderr})
+		return code, stderr.String()
+	}
+
+	if code, stderr := run(dir); code != cli.ExitOK {
+		t.Fatalf("install-links code = %d; stderr = %s", code, stderr)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if got := strings.Join(names, ","); got != "slug" {
+		t.Fatalf("linked %q, want %q", got, "slug")
+	}
+	if _, err := os.Readlink(filepath.Join(dir, "slug")); err != nil {
+		t.Fatalf("slug is not a link: %v", err)
+	}
+
+	if code, stderr := run(dir); code != cli.ExitFailure || !strings.Contains(stderr, "--force") {
+		t.Fatalf("install-links over existing links code = %d; stderr = %s", code, stderr)
+	}
+	if code, stderr := run("--force", "--aliases", dir); code != cli.ExitOK {
+		t.Fatalf("install-links --force code = %d; stderr = %s", code, stderr)
+	}
+	if _, err := os.Readlink(filepath.Join(dir, "s")); err != nil {
+		t.Fatalf("alias s is not a link: %v", err)
+	}
+}
@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Renderer writes a command's result in one output format.
+type Renderer func(w io.Writer, v any) error
+
+// Texter is implemented by results that have their own human-readable form.
+// Results without one are shown as YAML by the text renderer.
+type Texter interface {
+	Text() string
+}
+
+var renderers = map[string]Renderer{
+	"text": renderText,
+	"json": renderJSON,
+	"yaml": renderYAML,
+	"tsv":  renderTSV,
+}
+
+// RegisterRenderer adds or replaces the renderer for an output format.
+func RegisterRenderer(name string, r Renderer) {
+	renderers[name] = r
+}
+
+// OutputFormats returns the registered output format names, sorted.
+func OutputFormats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OutputFlag is the --output flag read by Context.Render. Register it as a
+// persistent flag on a root command.
+func OutputFlag() Flag {
+	return StringFlag("output", "o", "format", "Output format: "+strings.Join(OutputFormats(), ", ")).WithDefault("text")
+}
+
+// Render writes v to stdout in the format chosen by --output, or as text
+// when the command has no such flag.
+func (c *Context) Render(v any) error {
+	format := "text"
+	if c.flags != nil && c.flags.lookup("output") != nil {
+		format = c.String("output")
+	}
+
+	render, ok := renderers[format]
+	if !ok {
+		return Errorf(KindUsage, "unknown output format: %s%s", format, DidYouMean(format, OutputFormats()))
+	}
+	return render(c.Stdout, v)
+}
+
+func renderText(w io.Writer, v any) error {
+	switch v := v.(type) {
+	case Texter:
+		_, err := fmt.Fprintln(w, v.Text())
+		return err
+	case fmt.Stringer:
+		_, err := fmt.Fprintln(w, v.String())
+		return err
+	case string:
+		_, err := fmt.Fprintln(w, v)
+		return err
+	default:
+		return renderYAML(w, v)
+	}
+}
+
+func renderJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// member and object keep JSON object keys in the order they were encoded, so
+// YAML and TSV output follow struct field order.
+type member struct {
+	key   string
+	value any
+}
+
+type object []member
+
+// toTree encodes v as JSON, honoring json tags and custom marshalers, and
+// decodes it into objects, []any and scalars.
+func toTree(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeTree(dec)
+}
+
+func decodeTree(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			value, err := decodeTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	default:
+		return tok, nil
+	}
+}
+
+func renderYAML(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	writeYAML(&b, tree, 0)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeYAML(b *strings.Builder, node any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch node := node.(type) {
+	case object:
+		if len(node) == 0 {
+			b.WriteString(pad + "{}\n")
+			return
+		}
+		for _, m := range node {
+			b.WriteString(pad + yamlScalar(m.key) + ":")
+			writeYAMLValue(b, m.value, indent)
+		}
+	case []any:
+		if len(node) == 0 {
+			b.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range node {
+			b.WriteString(pad + "-")
+			writeYAMLItem(b, item, indent)
+		}
+	default:
+		b.WriteString(pad + yamlScalar(node) + "\n")
+	}
+}
+
+// writeYAMLValue finishes a "key:" line.
+func writeYAMLValue(b *strings.Builder, value any, indent int) {
+	switch value := value.(type) {
+	case object:
+		if len(value) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, value, indent+1)
+	case []any:
+		if len(value) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, value, indent)
+	default:
+		b.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+// writeYAMLItem finishes a "-" line, putting an object's first key on it.
+func writeYAMLItem(b *strings.Builder, item any, indent int) {
+	switch item := item.(type) {
+	case object:
+		if len(item) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		var rest strings.Builder
+		writeYAML(&rest, item, indent+1)
+		b.WriteString(" " + strings.TrimPrefix(rest.String(), strings.Repeat("  ", indent+1)))
+	case []any:
+		if len(item) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, item, indent+1)
+	default:
+		b.WriteString(" " + yamlScalar(item) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if yamlNeedsQuotes(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t")
+}
+
+// renderTSV writes a list of objects as a header row plus one row each, a
+// single object as one row, and scalars one per line. Nested values are
+// written as compact JSON.
+func renderTSV(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	var rows []object
+	switch tree := tree.(type) {
+	case object:
+		rows = []object{tree}
+	case []any:
+		for _, item := range tree {
+			obj, ok := item.(object)
+			if !ok {
+				for _, item := range tree {
+					if _, err := fmt.Fprintln(w, tsvCell(item)); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			rows = append(rows, obj)
+		}
+	default:
+		_, err := fmt.Fprintln(w, tsvCell(tree))
+		return err
+	}
+
+	var header []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, m := range row {
+			if !seen[m.key] {
+				seen[m.key] = true
+				header = append(header, m.key)
+			}
+		}
+	}
+	if len(header) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cells := make([]string, len(header))
+		for i, key := range header {
+			for _, m := range row {
+				if m.key == key {
+					cells[i] = tsvCell(m.value)
+				}
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tsvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.NewReplacer("\t", `\t`, "\n", `\n`).Replace(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		var b strings.Builder
+		writeJSON(&b, v)
+		return b.String()
+	}
+}
+
+// writeJSON re-encodes a decoded tree compactly, preserving key order.
+func writeJSON(b *strings.Builder, node any) {
+	switch node := node.(type) {
+	case object:
+		b.WriteString("{")
+		for i, m := range node {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			key, _ := json.Marshal(m.key)
+			b.Write(key)
+			b.WriteString(":")
+			writeJSON(b, m.value)
+		}
+		b.WriteString("}")
+	case []any:
+		b.WriteString("[")
+		for i, item := range node {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			writeJSON(b, item)
+		}
+		b.WriteString("]")
+	default:
+		value, _ := json.Marshal(node)
+		b.Write(value)
+	}
+}
@@ -0,0 +1,130 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+type outputAddress struct {
+	Address string `json:"address"`
+	IsIPv6  bool   `json:"is_ipv6"`
+}
+
+type outputInterface struct {
+	Name string          `json:"name"`
+	MAC  string          `json:"mac,omitempty"`
+	IPs  []outputAddress `json:"ips"`
+}
+
+type outputResult []outputInterface
+
+func (r outputResult) Text() string {
+	return r[0].Name + " " + r[0].IPs[0].Address
+}
+
+func newOutputCommand() *cli.Command {
+	return cli.New("tool", "Output test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		Register(
+			cli.New("ip", "Show addresses").WithRun(func(ctx *cli.Context, args []string) error {
+				return ctx.Render(outputResult{
+					{Name: "eth0", MAC: "02:00:00:00:00:01", IPs: []outputAddress{{Address: "192.0.2.2"}, {Address: "fd00::2", IsIPv6: true}}},
+					{Name: "wlan0", IPs: []outputAddress{}},
+				})
+			}),
+			cli.New("word", "Show a word").WithRun(func(ctx *cli.Context, args []string) error {
+				return ctx.Render(map[string]any{"word": "true", "count": 2})
+			}),
+		)
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "text uses Text",
+			args: []string{"ip"},
+			want: "eth0 192.0.2.2\n",
+		},
+		{
+			name: "json",
+			args: []string{"-o", "json", "ip"},
+			want: `[
+  {
+    "name": "eth0",
+    "mac": "02:00:00:00:00:01",
+    "ips": [
+      {
+        "address": "192.0.2.2",
+        "is_ipv6": false
+      },
+      {
+        "address": "fd00::2",
+        "is_ipv6": true
+      }
+    ]
+  },
+  {
+    "name": "wlan0",
+    "ips": []
+  }
+]
+`,
+		},
+		{
+			name: "yaml keeps field order",
+			args: []string{"ip", "--output=yaml"},
+			want: `- name: eth0
+  mac: 02:00:00:00:00:01
+  ips:
+  - address: 192.0.2.2
+    is_ipv6: false
+  - address: fd00::2
+    is_ipv6: true
+- name: wlan0
+  ips: []
+`,
+		},
+		{
+			name: "tsv",
+			args: []string{"ip", "-o", "tsv"},
+			want: "name\tmac\tips\n" +
+				"eth0\t02:00:00:00:00:01\t[{\"address\":\"192.0.2.2\",\"is_ipv6\":false},{\"address\":\"fd00::2\",\"is_ipv6\":true}]\n" +
+				"wlan0\t\t[]\n",
+		},
+		{
+			name: "text falls back to yaml and quotes ambiguous strings",
+			args: []string{"word"},
+			want: "count: 2\nword: \"true\"\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := cli.Execute(newOutputCommand(), tc.args, &stdout, &stderr); code != 0 {
+				t.Fatalf("Execute(%q) code = %d, stderr = %s", tc.args, code, stderr.String())
+			}
+			if got := stdout.String(); got != tc.want {
+				t.Fatalf("Execute(%q) =\n%s\nwant\n%s", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := cli.Execute(newOutputCommand(), []string{"-o", "jsno", "ip"}, &stdout, &stderr)
+	if code != cli.ExitUsage {
+		t.Fatalf("Execute() code = %d, want %d", code, cli.ExitUsage)
+	}
+	if want := `unknown output format: jsno (did you mean "json"?)`; !strings.Contains(stderr.String(), want) {
+		t.Fatalf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
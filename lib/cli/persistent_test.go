@@ -0,0 +1,104 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func newPersistentTree(out *string) *cli.Command {
+	return cli.New("tool", "Persistent flag test tool").
+		RegisterPersistentFlags(cli.BoolFlag("quiet", "q", "Suppress output")).
+		Register(
+			cli.New("net", "Network tools").
+				RegisterPersistentFlags(cli.StringFlag("iface", "i", "name", "Interface to use")).
+				Register(
+					cli.New("ip", "Show IP").
+						RegisterFlags(cli.BoolFlag("all", "a", "All addresses")).
+						WithRun(func(ctx *cli.Context, args []string) error {
+							*out = fmt.Sprintf("quiet=%v iface=%s all=%v", ctx.Bool("quiet"), ctx.String("iface"), ctx.Bool("all"))
+							return nil
+						}),
+				),
+		)
+}
+
+func TestExecuteParsesPersistentFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "before subcommands",
+			args: []string{"-q", "net", "--iface", "eth0", "ip"},
+			want: "quiet=true iface=eth0 all=false",
+		},
+		{
+			name: "after the leaf",
+			args: []string{"net", "ip", "-a", "--quiet", "-i", "wlan0"},
+			want: "quiet=true iface=wlan0 all=true",
+		},
+		{
+			name: "between subcommands",
+			args: []string{"net", "-q", "ip"},
+			want: "quiet=true iface= all=false",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			var stdout, stderr bytes.Buffer
+			if code := cli.Execute(newPersistentTree(&got), tc.args, &stdout, &stderr); code != 0 {
+				t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+			}
+			if got != tc.want {
+				t.Fatalf("Execute(%q) = %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsLeafFlagAboveLeaf(t *testing.T) {
+	var got string
+	var stdout, stderr bytes.Buffer
+	if code := cli.Execute(newPersistentTree(&got), []string{"net", "-a", "ip"}, &stdout, &stderr); code == 0 {
+		t.Fatalf("Execute() code = 0, want failure")
+	}
+	if !strings.Contains(stderr.String(), "unknown flag: -a") {
+		t.Fatalf("stderr = %q, want unknown flag error", stderr.String())
+	}
+}
+
+func TestHelpShowsInheritedFlags(t *testing.T) {
+	var got string
+	root := newPersistentTree(&got)
+	help := root.Subcommands[0].Subcommands[0].Help([]string{"tool", "net", "ip"})
+
+	flags := strings.Index(help, "FLAGS:")
+	inherited := strings.Index(help, "INHERITED FLAGS:")
+	if flags < 0 || inherited < 0 {
+		t.Fatalf("Help() missing flag sections:\n%s", help)
+	}
+	if !strings.Contains(help[inherited:], "--quiet") || !strings.Contains(help[inherited:], "--iface") {
+		t.Fatalf("Help() inherited section missing persistent flags:\n%s", help)
+	}
+	if strings.Contains(help[inherited:], "--all") {
+		t.Fatalf("Help() lists local flag as inherited:\n%s", help)
+	}
+}
+
+func TestValidateRejectsShadowedPersistentFlag(t *testing.T) {
+	cmd := cli.New("tool", "Tool").
+		RegisterPersistentFlags(cli.BoolFlag("quiet", "q", "Quiet")).
+		Register(cli.New("run", "Run").RegisterFlags(cli.BoolFlag("quick", "q", "Quick")).WithRun(noop))
+
+	err := cli.Validate(cmd)
+	if err == nil || !strings.Contains(err.Error(), "flag -q shadows inherited flag --quiet") {
+		t.Fatalf("Validate() = %v, want shadowed flag error", err)
+	}
+}
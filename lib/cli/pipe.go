@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// NewPipeCommand returns a command that runs a pipeline of the program's
+// own commands in-process, streaming each stage's output into the next.
+func NewPipeCommand(name string) *Command {
+	return New(name, "Run commands as a pipeline without a shell").
+		WithArgs(VariadicArg("pipeline")).
+		RegisterFlags(BoolFlag("dry-run", "n", "Print the resolved stages without running them")).
+		WithSections(Section{
+			Title: "PIPELINES",
+			Lines: []string{
+				"Stages are separated by | and run at once, each reading the output of the",
+				"one before it as piped stdin. The first stage reads this command's stdin.",
+				"Quote the pipeline as one argument so your shell does not run it, or pass",
+				"the words after -- with each | as a word of its own.",
+			},
+		}).
+		WithExampleSpecs(
+			Example{Args: []string{`"paste | case kebab | copy"`}, Note: "Kebab-case the clipboard in place", External: true},
+			Example{Args: []string{`"case title 'war and peace' | slug"`}, Output: "war-and-peace"},
+			Example{Args: []string{"--dry-run", `"paste | case kebab | copy"`}, Note: "Show the stages without running them"},
+		).
+		WithRun(runPipe).
+		AsUtility()
+}
+
+// pipeStage is one resolved stage, as --dry-run shows it.
+type pipeStage struct {
+	Stage   int      `json:"stage"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+
+	argv []string
+}
+
+type pipeStages []pipeStage
+
+func (s pipeStages) Text() string {
+	width := 0
+	for _, stage := range s {
+		width = max(width, len(stage.Command))
+	}
+	lines := make([]string, len(s))
+	for i, stage := range s {
+		lines[i] = strings.TrimRight(fmt.Sprintf("%d  %-*s  %s", stage.Stage, width, stage.Command, JoinArgs(stage.Args)), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runPipe(ctx *Context, args []string) error {
+	words, err := pipelineStages(ctx.Variadic("pipeline"))
+	if err != nil {
+		return NewError(KindUsage, err)
+	}
+	if len(words) == 0 {
+		return Errorf(KindUsage, "pipeline required")
+	}
+
+	stages := make(pipeStages, len(words))
+	for i, argv := range words {
+		if stages[i], err = resolveStage(ctx.Root, ctx.Path[0], argv, ctx.configMacros); err != nil {
+			return Errorf(KindUsage, "stage %d (%s): %v", i+1, JoinArgs(argv), err)
+		}
+		stages[i].Stage = i + 1
+	}
+
+	if ctx.Bool("dry-run") {
+		return ctx.Render(stages)
+	}
+	return runStages(ctx, stages)
+}
+
+// pipelineStages splits a pipeline given as one argument, or as words with
+// | between stages, into each stage's arguments.
+func pipelineStages(args []string) ([][]string, error) {
+	if len(args) == 1 {
+		return splitPipeline(args[0])
+	}
+
+	var stages [][]string
+	var stage []string
+	for _, arg := range args {
+		if arg == "|" {
+			stages = append(stages, stage)
+			stage = nil
+			continue
+		}
+		stage = append(stage, arg)
+	}
+	if len(args) > 0 {
+		stages = append(stages, stage)
+	}
+	return stages, checkStages(stages)
+}
+
+// splitPipeline cuts s at every unquoted | and splits each stage like
+// SplitArgs.
+func splitPipeline(s string) ([][]string, error) {
+	var stages [][]string
+	start := 0
+	var quote rune
+	escaped := false
+	cut := func(end int) error {
+		args, err := SplitArgs(s[start:end])
+		if err != nil {
+			return err
+		}
+		stages = append(stages, args)
+		return nil
+	}
+
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			}
+		case r == '\\':
+			escaped = true
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '|':
+			if err := cut(i); err != nil {
+				return nil, err
+			}
+			start = i + 1
+		}
+	}
+	if err := cut(len(s)); err != nil {
+		return nil, err
+	}
+	if len(stages) == 1 && len(stages[0]) == 0 {
+		return nil, nil
+	}
+	return stages, checkStages(stages)
+}
+
+func checkStages(stages [][]string) error {
+	for i, stage := range stages {
+		if len(stage) == 0 {
+			return fmt.Errorf("stage %d is empty", i+1)
+		}
+	}
+	return nil
+}
+
+// resolveStage finds the command a stage runs, the way Execute would, so a
+// mistyped stage fails before any stage starts.
+func resolveStage(root *Command, program string, argv []string, configMacros []Macro) (pipeStage, error) {
+	args := argv
+	expanded := make(map[string]bool)
+	for root.findSubcommand(args[0]) == nil {
+		macro, ok := root.findMacro(args[0], configMacros)
+		if !ok || expanded[strings.ToLower(macro.Name)] {
+			break
+		}
+		expanded[strings.ToLower(macro.Name)] = true
+		args = append(append([]string{}, macro.Args...), args[1:]...)
+	}
+
+	if root.findSubcommand(args[0]) == nil && root.findPlugin(args[0]) != "" {
+		return pipeStage{Command: root.pluginPrefix() + args[0], Args: args[1:], argv: argv}, nil
+	}
+
+	target := resolveExample(root, []string{program}, nil, args)
+	if !target.help {
+		if len(target.remaining) > 0 && len(target.command.Subcommands) > 0 && target.command.findSubcommand(target.remaining[0]) == nil {
+			if _, err := target.command.matchSubcommand(target.remaining[0]); err != nil {
+				return pipeStage{}, err
+			}
+			return pipeStage{}, fmt.Errorf("unknown %s: %s%s", unknownLabel(target.path), target.remaining[0], didYouMean(target.command.suggestSubcommands(target.remaining[0])))
+		}
+		if target.command.Run == nil {
+			return pipeStage{}, fmt.Errorf("%s needs a %s", strings.Join(target.path, " "), target.command.SubcommandName)
+		}
+		if err := validateExampleFlags(target); err != nil {
+			return pipeStage{}, err
+		}
+	}
+	return pipeStage{Command: strings.Join(target.path, " "), Args: target.remaining, argv: argv}, nil
+}
+
+var (
+	// errStageDone closes a stage's input once the stage has exited,
+	// failing writes from the stages before it.
+	errStageDone = errors.New("next stage exited")
+	// errStageFailed closes a stage's output when it fails, so the next
+	// stage does not mistake a partial input for a whole one.
+	errStageFailed = errors.New("previous stage failed")
+)
+
+// pipeline tracks which stages still matter. After the first failure, and
+// for stages whose reader has exited, further errors are consequences, so
+// they are neither reported nor printed.
+type pipeline struct {
+	mu     sync.Mutex
+	stderr io.Writer
+	failed int // first failed stage, -1 for none
+	code   int
+	cutoff int // stages before cutoff have lost their reader
+}
+
+func (p *pipeline) finish(i, code int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if code == ExitOK || p.failed >= 0 || i < p.cutoff {
+		if code == ExitOK {
+			p.cutoff = max(p.cutoff, i)
+		}
+		return false
+	}
+	p.failed, p.code = i, code
+	return true
+}
+
+// stageStderr is one stage's stderr, muted once it no longer matters.
+type stageStderr struct {
+	p     *pipeline
+	stage int
+}
+
+func (w stageStderr) Write(b []byte) (int, error) {
+	w.p.mu.Lock()
+	defer w.p.mu.Unlock()
+	if w.p.failed >= 0 || w.stage < w.p.cutoff {
+		return len(b), nil
+	}
+	return w.p.stderr.Write(b)
+}
+
+func runStages(ctx *Context, stages pipeStages) error {
+	runCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+
+	p := &pipeline{stderr: ctx.Stderr, failed: -1}
+	var stdin io.Reader
+	if ctx.Piped() {
+		stdin = ctx.Stdin
+	}
+
+	// Stages feed each other text whatever format is configured; only the
+	// last prints in the pipeline's own format.
+	format := ""
+	if ctx.flags != nil && ctx.flags.lookup("output") != nil {
+		format = ctx.String("output")
+	}
+
+	var wg sync.WaitGroup
+	for i, stage := range stages {
+		var stdout io.Writer = ctx.Stdout
+		var next *io.PipeReader
+		var writer *io.PipeWriter
+		output := format
+		if i < len(stages)-1 {
+			next, writer = io.Pipe()
+			stdout = writer
+			output = "text"
+		}
+		argv := stage.argv
+		if format != "" {
+			argv = append([]string{"--output=" + output}, argv...)
+		}
+
+		wg.Add(1)
+		go func(i int, argv []string, stdin io.Reader) {
+			defer wg.Done()
+			code := ExecuteWith(ctx.Root, argv, Options{
+				Context: runCtx,
+				Program: ctx.Path[0],
+				Stdin:   stdin,
+				Stdout:  stdout,
+				Stderr:  stageStderr{p: p, stage: i},
+			})
+			if p.finish(i, code) {
+				cancel()
+			}
+			if writer != nil && code != ExitOK {
+				writer.CloseWithError(errStageFailed)
+			} else if writer != nil {
+				writer.Close()
+			}
+			if reader, ok := stdin.(*io.PipeReader); ok {
+				reader.CloseWithError(errStageDone)
+			}
+		}(i, argv, stdin)
+		stdin = next
+	}
+	wg.Wait()
+
+	if p.failed < 0 {
+		return nil
+	}
+	kind := kindOfExitCode(p.code)
+	if kind == KindUsage {
+		// The stage printed its own usage; the pipeline's was fine.
+		kind = KindFailure
+	}
+	return Errorf(kind, "stage %d (%s) failed", p.failed+1, JoinArgs(stages[p.failed].argv))
+}
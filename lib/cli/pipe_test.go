@@ -0,0 +1,53 @@
+package cli_test
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func newPipeRoot() *cli.Command {
+	return cli.New("tool", "Pipe test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		WithConfig().
+		Register(
+			cli.New("echo", "Echo words").WithArgs(cli.VariadicArg("words")).WithRun(func(ctx *cli.Context, args []string) error {
+				return ctx.Render(strings.Join(args, " "))
+			}),
+			newUpperCommand("upper"),
+			cli.New("spam", "Print lines forever").WithRun(func(ctx *cli.Context, args []string) error {
+				for i := 0; ; i++ {
+					if _, err := fmt.Fprintln(ctx.Stdout, i); err != nil {
+						return err
+					}
+				}
+			}),
+			cli.New("first", "Print the first line of stdin").WithRun(func(ctx *cli.Context, args []string) error {
+				line, _ := bufio.NewReader(ctx.Stdin).ReadString('\n')
+				return ctx.Render(strings.TrimSpace(line))
+			}),
+			newFailCommand(),
+			cli.NewPipeCommand("pipe"),
+		)
+}
+
+func TestPipe(t *testing.T) {
+	clitest.RunGolden(t, newPipeRoot(),
+		clitest.Case{Name: "pipe-stream", Args: []string{"pipe", "echo a b | upper"}},
+		clitest.Case{Name: "pipe-words", Args: []string{"pipe", "--", "echo", "a b", "|", "upper"}},
+		clitest.Case{Name: "pipe-quoted-bar", Args: []string{"pipe", `echo 'x|y' "|" \| | upper`}},
+		clitest.Case{Name: "pipe-stdin", Args: []string{"pipe", "upper | first"}, Stdin: "hi\n"},
+		clitest.Case{Name: "pipe-reader-exits-early", Args: []string{"pipe", "spam | first"}},
+		clitest.Case{Name: "pipe-dry-run", Args: []string{"pipe", "-n", "echo a | upper"}},
+		clitest.Case{Name: "pipe-unknown-stage", Args: []string{"pipe", "echo a | uper"}},
+		clitest.Case{Name: "pipe-empty-stage", Args: []string{"pipe", "echo a | | upper"}},
+		clitest.Case{Name: "pipe-bad-flag", Args: []string{"pipe", "echo --nope"}},
+		clitest.Case{Name: "pipe-failed-stage", Args: []string{"pipe", "echo a | fail | upper"}},
+		clitest.Case{Name: "pipe-output", Args: []string{"-o", "json", "pipe", "echo a b | upper"}},
+		clitest.Case{Name: "pipe-configured-output", Args: []string{"pipe", "echo a b | upper"}, Env: map[string]string{"TOOL_OUTPUT": "json"}},
+	)
+}
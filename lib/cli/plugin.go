@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pluginGracePeriod is how long a cancelled plugin has to exit after it is
+// interrupted before it is killed.
+const pluginGracePeriod = 3 * time.Second
+
+// WithPlugins makes a root command fall back to running "<name>-<command>"
+// from PATH, git style, for commands it does not define itself. Arguments,
+// stdio and the exit code pass through untouched.
+func (c *Command) WithPlugins() *Command {
+	c.Plugins = true
+	return c
+}
+
+type plugin struct {
+	name string
+	path string
+}
+
+func (p plugin) description() string {
+	return "[plugin] " + p.path
+}
+
+func (c *Command) pluginPrefix() string {
+	return c.Name + "-"
+}
+
+// findPlugin returns the executable for the plugin named name, or "" when
+// there is none on PATH.
+func (c *Command) findPlugin(name string) string {
+	if !c.Plugins || name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+	path, err := exec.LookPath(c.pluginPrefix() + name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// discoverPlugins lists the plugins on PATH, sorted by name. The first match
+// on PATH wins, as it does when running one, and names the command already
+// defines are skipped since they never reach a plugin.
+func (c *Command) discoverPlugins() []plugin {
+	if !c.Plugins {
+		return nil
+	}
+
+	prefix := c.pluginPrefix()
+	seen := make(map[string]bool)
+	var plugins []plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(pluginFileName(entry.Name()), prefix)
+			if !ok || name == "" || seen[name] || c.findSubcommand(name) != nil {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, plugin{name: name, path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].name < plugins[j].name })
+	return plugins
+}
+
+// pluginFileName strips the executable extension Windows needs.
+func pluginFileName(file string) string {
+	if runtime.GOOS != "windows" {
+		return file
+	}
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, pathExt := range filepath.SplitList(os.Getenv("PATHEXT")) {
+		if ext != "" && ext == strings.ToLower(pathExt) {
+			return strings.TrimSuffix(file, filepath.Ext(file))
+		}
+	}
+	return ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0o111 != 0
+}
+
+// runPlugin runs the plugin at path. Cancelling ctx interrupts it, then kills
+// it if it is still running after pluginGracePeriod.
+func runPlugin(ctx context.Context, path string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Cancel = func() error {
+		// Interrupting a process is not supported everywhere, such as on
+		// Windows; kill it there instead.
+		if err := cmd.Process.Signal(os.Interrupt); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = pluginGracePeriod
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
@@ -0,0 +1,148 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+// writePlugin puts an executable script named file into a fresh directory
+// on PATH.
+func writePlugin(t *testing.T, file, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return path
+}
+
+func newPluginRoot() *cli.Command {
+	return cli.New("tool", "Plugin test tool").
+		WithPlugins().
+		WithPrefixMatching().
+		Register(
+			cli.New("slug", "Slug text").WithRun(func(ctx *cli.Context, args []string) error {
+				return ctx.Render("builtin")
+			}),
+		)
+}
+
+func TestExecutePlugin(t *testing.T) {
+	writePlugin(t, "tool-hello", "echo \"args: $*\"\ncat\necho oops >&2\nexit 3\n")
+
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(newPluginRoot(), []string{"hello", "a", "--flag"}, cli.Options{
+		Stdin:  strings.NewReader("from stdin\n"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if code != 3 {
+		t.Fatalf("ExecuteWith() code = %d, want 3; stderr = %s", code, stderr.String())
+	}
+	if got, want := stdout.String(), "args: a --flag\nfrom stdin\n"; got != want {
+		t.Fatalf("stdout = %q, want %q", got, want)
+	}
+	if got, want := stderr.String(), "oops\n"; got != want {
+		t.Fatalf("stderr = %q, want %q", got, want)
+	}
+}
+
+// cancelOnWrite cancels a context on the first write, once the writer's
+// process is known to be running.
+type cancelOnWrite struct {
+	io.Writer
+	cancel context.CancelFunc
+}
+
+func (w cancelOnWrite) Write(p []byte) (int, error) {
+	defer w.cancel()
+	return w.Writer.Write(p)
+}
+
+func TestExecutePluginInterrupted(t *testing.T) {
+	writePlugin(t, "tool-wait", "trap 'echo cleaned up; exit 130' INT\necho ready\nwhile :; do sleep 0.05; done\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(newPluginRoot(), []string{"wait"}, cli.Options{
+		Context: ctx,
+		Stdout:  cancelOnWrite{Writer: &stdout, cancel: cancel},
+		Stderr:  &stderr,
+	})
+	if code != cli.ExitInterrupted {
+		t.Fatalf("ExecuteWith() code = %d, want %d; stderr = %s", code, cli.ExitInterrupted, stderr.String())
+	}
+	if got, want := stdout.String(), "ready\ncleaned up\n"; got != want {
+		t.Fatalf("stdout = %q, want %q; the plugin should get to clean up", got, want)
+	}
+}
+
+func TestExecutePluginPrecedence(t *testing.T) {
+	writePlugin(t, "tool-slug", "echo plugin\n")
+	writePlugin(t, "tool-sl", "echo plugin sl\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "builtin wins", args: []string{"slug"}, want: "builtin\n"},
+		{name: "exact plugin beats prefix", args: []string{"sl"}, want: "plugin sl\n"},
+		{name: "prefix without plugin", args: []string{"slu"}, want: "builtin\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.ExecuteWith(newPluginRoot(), tc.args, cli.Options{Stdout: &stdout, Stderr: &stderr})
+			if code != cli.ExitOK {
+				t.Fatalf("ExecuteWith(%q) code = %d; stderr = %s", tc.args, code, stderr.String())
+			}
+			if got := stdout.String(); got != tc.want {
+				t.Fatalf("ExecuteWith(%q) = %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPluginHelp(t *testing.T) {
+	path := writePlugin(t, "tool-deploy", "exit 0\n")
+	writePlugin(t, "tool-slug", "exit 0\n")
+
+	help := newPluginRoot().Help([]string{"tool"})
+	if !strings.Contains(help, "deploy  [plugin] "+path) {
+		t.Fatalf("help does not list the deploy plugin:\n%s", help)
+	}
+	if strings.Contains(help, "tool-slug") {
+		t.Fatalf("help lists a plugin shadowed by a builtin:\n%s", help)
+	}
+
+	help = cli.New("tool", "No plugins").Register(cli.New("slug", "Slug text").WithRun(noop)).Help([]string{"tool"})
+	if strings.Contains(help, "deploy") {
+		t.Fatalf("help lists plugins without WithPlugins:\n%s", help)
+	}
+}
+
+func TestExecuteUnknownWithoutPlugin(t *testing.T) {
+	writePlugin(t, "tool-deploy", "exit 0\n")
+
+	var stdout, stderr bytes.Buffer
+	root := cli.New("tool", "No plugins").Register(cli.New("slug", "Slug text").WithRun(noop))
+	if code := cli.ExecuteWith(root, []string{"deploy"}, cli.Options{Stdout: &stdout, Stderr: &stderr}); code != cli.ExitUsage {
+		t.Fatalf("ExecuteWith() code = %d, want %d", code, cli.ExitUsage)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// recordFlagNames are the flags WithRecords adds. Tools served to programs
+// leave them out, since records stream text rather than a result.
+var recordFlagNames = []string{"each-line", "null", "pairs"}
+
+// WithRecords adds the --each-line, --null and --pairs flags read by
+// Context.Records and Context.EachRecord to a command that converts text.
+func (c *Command) WithRecords() *Command {
+	return c.RegisterFlags(
+		BoolFlag("each-line", "l", "Convert each line of the input on its own, streaming the results"),
+		BoolFlag("null", "z", "Like --each-line, with records separated by NUL instead of newline"),
+		BoolFlag("pairs", "", "With --each-line or --null, print input<TAB>output for each record"),
+	).WithPreRun(checkRecords)
+}
+
+func checkRecords(ctx *Context) error {
+	if ctx.Bool("pairs") && !ctx.Records() {
+		return Errorf(KindUsage, "--pairs needs --each-line or --null")
+	}
+	// Only --output given on the command line conflicts; a configured
+	// format is for the command's other runs.
+	if ctx.Records() && ctx.IsSet("output") && ctx.String("output") != "text" {
+		return Errorf(KindUsage, "--each-line and --null print text, not %s; use --pairs to keep inputs with their outputs", ctx.String("output"))
+	}
+	return nil
+}
+
+// Records reports whether the input should be converted record by record.
+func (c *Context) Records() bool {
+	return c.Bool("each-line") || c.Bool("null")
+}
+
+// EachRecord reads the named input, from stdin as Input would, as
+// newline- or NUL-separated records and writes convert's result for each
+// as it goes, without reading the whole input first. Records are trimmed of
+// surrounding space, and empty ones are passed through without calling
+// convert, so output records line up with input records. A record that
+// fails prints its error and an empty result; EachRecord then reports how
+// many failed.
+func (c *Context) EachRecord(name string, convert func(string) (string, error)) error {
+	var input io.Reader = strings.NewReader(c.Arg(name))
+	if c.fromStdin(name) {
+		input = c.Stdin
+	}
+
+	delim, label := byte('\n'), "line"
+	if c.Bool("null") {
+		delim, label = 0, "record"
+	}
+
+	r := bufio.NewReader(input)
+	w := bufio.NewWriter(c.Stdout)
+	records, failed := 0, 0
+	for {
+		record, err := r.ReadString(delim)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("read stdin: %w", err)
+		}
+		if record == "" && err == io.EOF {
+			break
+		}
+		records++
+		if ctxErr := c.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		record = strings.TrimSpace(strings.TrimSuffix(record, string(delim)))
+		var output string
+		if record != "" {
+			var convertErr error
+			if output, convertErr = convert(record); convertErr != nil {
+				failed++
+				fmt.Fprintf(c.Stderr, "Error: %s %d: %v\n", label, records, convertErr)
+			}
+		}
+
+		if c.Bool("pairs") {
+			w.WriteString(record + "\t")
+		}
+		w.WriteString(output)
+		w.WriteByte(delim)
+		// Flush before a read that may block, so results stream to a reader
+		// as soon as their records arrive.
+		if buffered, _ := r.Peek(r.Buffered()); bytes.IndexByte(buffered, delim) < 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return Errorf(KindFailure, "%d of %d %ss failed", failed, records, label)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func newRecordsRoot() *cli.Command {
+	return cli.New("tool", "Records test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		WithConfig().
+		Register(newUpperCommand("upper").WithRecords())
+}
+
+func TestEachRecord(t *testing.T) {
+	clitest.RunGolden(t, newRecordsRoot(),
+		clitest.Case{Name: "records-each-line", Args: []string{"upper", "-l"}, Stdin: "a b\n\nc\n"},
+		clitest.Case{Name: "records-null-pairs", Args: []string{"upper", "-z", "--pairs"}, Stdin: "a\x00b\x00"},
+		clitest.Case{Name: "records-pairs-alone", Args: []string{"upper", "--pairs", "x"}},
+		clitest.Case{Name: "records-output", Args: []string{"-o", "json", "upper", "-l", "x"}},
+		clitest.Case{Name: "records-configured-output", Args: []string{"upper", "-l"}, Stdin: "a\nb\n", Env: map[string]string{"TOOL_OUTPUT": "json"}},
+	)
+}
+
+func TestEachRecordStreams(t *testing.T) {
+	in, input := io.Pipe()
+	output, out := io.Pipe()
+	done := make(chan int, 1)
+	go func() {
+		defer out.Close()
+		done <- cli.ExecuteWith(newRecordsRoot(), []string{"upper", "--each-line"}, cli.Options{
+			Program: "tool",
+			Stdin:   in,
+			Stdout:  out,
+			Stderr:  new(bytes.Buffer),
+		})
+	}()
+
+	// Each result must arrive before the next record is written.
+	lines := bufio.NewReader(output)
+	for _, record := range []string{"a b", "", "c"} {
+		if _, err := io.WriteString(input, record+"\n"); err != nil {
+			t.Fatal(err)
+		}
+		got, err := lines.ReadString('\n')
+		if want := strings.ToUpper(record) + "\n"; err != nil || got != want {
+			t.Fatalf("record %q = %q, %v; want %q", record, got, err, want)
+		}
+	}
+	input.Close()
+
+	if rest, _ := io.ReadAll(lines); len(rest) > 0 {
+		t.Fatalf("unexpected output after the last record: %q", rest)
+	}
+	if code := <-done; code != cli.ExitOK {
+		t.Fatalf("exit code = %d, want %d", code, cli.ExitOK)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/khinshankhan/yui/cmd/color/colorcli"
 	"github.com/khinshankhan/yui/cmd/net/netcli"
 	"github.com/khinshankhan/yui/cmd/slug/slugcli"
+	"github.com/khinshankhan/yui/cmd/sound/soundcli"
+	"github.com/khinshankhan/yui/cmd/yui/yuicli"
 	"github.com/khinshankhan/yui/lib/cli"
 )
 
@@ -44,19 +46,13 @@ func TestCommandRegistrationsAreValid(t *testing.T) {
 			name: "net",
 			cmd:  netcli.NewCommand("net", "n"),
 		},
+		{
+			name: "sound",
+			cmd:  soundcli.NewCommand("sound", "snd"),
+		},
 		{
 			name: "yui",
-			cmd: cli.New("yui", "A collection of micro tools").
-				WithSubcommandName("command").
-				Register(
-					casecli.NewCommand("case", "c"),
-					slugcli.NewCommand("slug", "s"),
-					clipcli.NewCommand("clip", "clipboard"),
-					clipcli.NewCopyCommand("copy"),
-					clipcli.NewPasteCommand("paste"),
-					colorcli.NewCommand("color", "col"),
-					netcli.NewCommand("net", "n"),
-				),
+			cmd:  yuicli.NewCommand("yui"),
 		},
 	}
 
@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// mcpProtocolVersions are the Model Context Protocol revisions serve speaks,
+// newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// NewServeCommand returns a command that offers the program's commands to
+// other programs as tools, without starting a process per call.
+func NewServeCommand(name string) *Command {
+	return New(name, "Serve commands as tools to other programs").
+		RegisterFlags(
+			BoolFlag("stdio", "", "Speak JSON-RPC 2.0 and the Model Context Protocol on stdin and stdout"),
+			StringFlag("http", "", "addr", "Serve a JSON API for the pure commands on addr, such as localhost:7777"),
+			StringFlag("cors", "", "origin", "Let pages from origin, such as http://localhost:3000, call the HTTP API; * lets any page"),
+		).
+		WithSections(Section{
+			Title: "TOOLS",
+			Lines: []string{
+				"Every command that runs, other than utilities such as this one and commands",
+				"marked unserved, is a tool named after its path with _ between words, such",
+				"as case or clip_copy. A shortcut to another command is served once, under",
+				"the longer name.",
+				"A tool takes one JSON object: its arguments and flags by name. Arguments",
+				"the command reads from stdin take the whole text as a string.",
+				"With --stdio, requests and responses are JSON-RPC 2.0 messages, one per line;",
+				"batch arrays are refused.",
+				"MCP clients use initialize, tools/list and tools/call. Other clients may call",
+				"a tool by its name as the method, with its object as params.",
+				"With --http, each pure tool, one that only computes, is POST /<path> with",
+				"the object as the body, such as POST /case. Errors are {\"error\", \"exit\"}",
+				"objects, and GET /openapi.json describes every endpoint. Browsers only let",
+				"pages call the API from the origin given with --cors.",
+			},
+		}).
+		WithExampleSpecs(
+			Example{Args: []string{"--stdio"}, Note: "Serve tools to an editor or agent on stdio", External: true},
+			Example{Args: []string{"--http", "localhost:7777"}, Note: "Serve the pure tools on port 7777", External: true},
+			Example{Args: []string{"--http", "localhost:7777", "--cors", "http://localhost:3000"}, Note: "Let a page on port 3000 call them", External: true},
+		).
+		WithRun(runServe).
+		AsUtility()
+}
+
+func runServe(ctx *Context, args []string) error {
+	addr := ctx.String("http")
+	switch {
+	case ctx.Bool("stdio") && addr != "":
+		return Errorf(KindUsage, "use one of --stdio and --http")
+	case addr != "":
+		return runServeHTTP(ctx, addr)
+	case !ctx.Bool("stdio"):
+		return Errorf(KindUsage, "serve needs --stdio or --http")
+	}
+	s := &rpcServer{
+		root:    ctx.Root,
+		program: ctx.Path[0],
+		tools:   collectTools(ctx.Root),
+		config:  requestConfig(ctx.Root),
+		out:     json.NewEncoder(ctx.Stdout),
+		calls:   make(map[string]context.CancelFunc),
+	}
+	return s.serve(ctx.Context(), ctx.Stdin)
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcToolFailed     = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type mcpTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema *jsonSchema `json:"inputSchema"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpCallResult struct {
+	Content           []mcpContent    `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError"`
+}
+
+// rpcServer answers JSON-RPC requests read one per line. Requests run
+// concurrently, so responses may come back out of order, matched by id.
+type rpcServer struct {
+	root    *Command
+	program string
+	tools   []tool
+	config  *runConfig
+
+	mu       sync.Mutex
+	out      *json.Encoder
+	calls    map[string]context.CancelFunc // running requests by id
+	writeErr error                         // the first failed response write
+	stop     context.CancelFunc            // ends serve after a failed write
+}
+
+// serve reads requests until in ends, ctx is cancelled, or a response
+// cannot be written, which leaves the client nothing to read.
+func (s *rpcServer) serve(ctx context.Context, in io.Reader) error {
+	ctx, s.stop = context.WithCancel(ctx)
+	defer s.stop()
+
+	var wg sync.WaitGroup
+	err := s.read(ctx, in, &wg)
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	return err
+}
+
+func (s *rpcServer) read(ctx context.Context, in io.Reader, wg *sync.WaitGroup) error {
+	next := lineReader(ctx, in)
+	for {
+		line, err := next()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if req, ok := s.decode(line); ok {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.handle(ctx, req)
+				}()
+			}
+		}
+		if err != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// decode parses one message, answering it with an error when it is not a
+// request. Batches, JSON arrays of requests, are refused as invalid.
+func (s *rpcServer) decode(line []byte) (rpcRequest, bool) {
+	var req rpcRequest
+	line = bytes.TrimSpace(line)
+	if !json.Valid(line) {
+		var v any
+		err := json.Unmarshal(line, &v)
+		s.reply(nil, nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+		return req, false
+	}
+	if line[0] == '[' {
+		s.reply(nil, nil, &rpcError{Code: rpcInvalidRequest, Message: "batch requests are not supported; send one request per line"})
+		return req, false
+	}
+	if err := json.Unmarshal(line, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+		s.reply(req.ID, nil, &rpcError{Code: rpcInvalidRequest, Message: `want a "jsonrpc": "2.0" request with a method`})
+		return req, false
+	}
+	return req, true
+}
+
+func (s *rpcServer) handle(ctx context.Context, req rpcRequest) {
+	if req.ID == nil {
+		s.notify(req)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.calls[string(req.ID)] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.calls, string(req.ID))
+		s.mu.Unlock()
+	}()
+
+	result, err := s.dispatch(ctx, req)
+	var rpcErr *rpcError
+	if err != nil && !errors.As(err, &rpcErr) {
+		rpcErr = &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	s.reply(req.ID, result, rpcErr)
+}
+
+// notify handles a notification, which gets no response.
+func (s *rpcServer) notify(req rpcRequest) {
+	if req.Method != "notifications/cancelled" {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.calls[string(params.RequestID)]; ok {
+		cancel()
+	}
+}
+
+func (s *rpcServer) dispatch(ctx context.Context, req rpcRequest) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		tools := make([]mcpTool, len(s.tools))
+		for i, t := range s.tools {
+			tools[i] = mcpTool{Name: t.Name, Description: t.command.Description, InputSchema: t.inputSchema()}
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string                     `json:"name"`
+			Arguments map[string]json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, err
+		}
+		t, ok := findTool(s.tools, params.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown tool: %s%s", params.Name, DidYouMean(params.Name, s.toolNames()))
+		}
+		return mcpResult(t.call(ctx, s.root, s.program, s.config, params.Arguments)), nil
+	}
+
+	t, ok := findTool(s.tools, req.Method)
+	if !ok {
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method: " + req.Method + DidYouMean(req.Method, s.toolNames())}
+	}
+	var arguments map[string]json.RawMessage
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &arguments); err != nil {
+			return nil, fmt.Errorf("params must be an object of arguments: %w", err)
+		}
+	}
+	result := t.call(ctx, s.root, s.program, s.config, arguments)
+	if result.Exit != ExitOK {
+		code := rpcToolFailed
+		if result.Exit == ExitUsage {
+			code = rpcInvalidParams
+		}
+		return nil, &rpcError{Code: code, Message: result.Error, Data: map[string]int{"exit": result.Exit}}
+	}
+	if structured, ok := result.structured(); ok {
+		return structured, nil
+	}
+	return result.Output, nil
+}
+
+func (s *rpcServer) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+	version := mcpProtocolVersions[0]
+	if slices.Contains(mcpProtocolVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+
+	info := map[string]string{"name": s.root.Name, "version": "dev"}
+	if s.root.Version != nil && s.root.Version.Version != "" {
+		info["version"] = s.root.Version.Version
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{"tools": map[string]bool{"listChanged": false}},
+		"serverInfo":      info,
+	}, nil
+}
+
+// mcpResult reports a tool call the way MCP expects: failures are results
+// flagged isError, so the caller can read the message.
+func mcpResult(r toolResult) mcpCallResult {
+	if r.Exit != ExitOK {
+		return mcpCallResult{Content: []mcpContent{{Type: "text", Text: r.Error}}, IsError: true}
+	}
+	result := mcpCallResult{Content: []mcpContent{{Type: "text", Text: string(bytes.TrimSpace([]byte(r.Output)))}}}
+	structured, ok := r.structured()
+	switch {
+	case !ok:
+	case structured[0] == '{':
+		// structuredContent must be an object; the text carries it too.
+		result.StructuredContent = structured
+	case structured[0] == '"':
+		json.Unmarshal(structured, &result.Content[0].Text)
+	}
+	return result
+}
+
+func (s *rpcServer) toolNames() []string {
+	names := make([]string, len(s.tools))
+	for i, t := range s.tools {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func (s *rpcServer) reply(id json.RawMessage, result any, err *rpcError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	resp := rpcResponse{JSONRPC: "2.0", ID: id, Result: result}
+	if err != nil {
+		resp = rpcResponse{JSONRPC: "2.0", ID: id, Error: err}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writeErr != nil {
+		return
+	}
+	if err := s.out.Encode(resp); err != nil {
+		s.writeErr = fmt.Errorf("write response: %w", err)
+		s.stop()
+	}
+}
@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxRequestBody caps the JSON a tool call may send over HTTP.
+const maxRequestBody = 1 << 20
+
+// AsPure marks a command, and the commands below it, as a pure function of
+// its arguments, touching nothing on the machine. Only pure commands are
+// served over HTTP, where any page the browser opens can reach them.
+func (c *Command) AsPure() *Command {
+	c.Pure = true
+	return c
+}
+
+// isPure reports whether c or one of its ancestors is pure.
+func (c *Command) isPure() bool {
+	for p := c; p != nil; p = p.parent {
+		if p.Pure {
+			return true
+		}
+	}
+	return false
+}
+
+func runServeHTTP(ctx *Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return NewError(KindUnavailable, err)
+	}
+	srv := &http.Server{
+		Handler:           newHTTPAPI(ctx.Root, ctx.Path[0], ctx.String("cors"), ctx.Stderr),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx.Context() },
+	}
+	fmt.Fprintf(ctx.Stderr, "Serving http://%s (OpenAPI at /openapi.json)\n", ln.Addr())
+
+	// The server shuts down on cancellation, or once Serve fails.
+	serving, stop := context.WithCancel(ctx.Context())
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-serving.Done()
+		shutdown, cancel := context.WithTimeout(context.WithoutCancel(ctx.Context()), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-done
+	return nil
+}
+
+// HTTPHandler serves the pure commands in root's tree as a JSON API: each
+// is POST /<command path>, taking the tool's arguments as a JSON object and
+// answering with its JSON result. GET /openapi.json describes them all.
+// Browsers allow pages from the cors origin, or any page for "*", to call
+// the API; empty allows none. Responses that fail to send are reported on
+// os.Stderr.
+func HTTPHandler(root *Command, program, cors string) http.Handler {
+	return newHTTPAPI(root, program, cors, os.Stderr)
+}
+
+type httpAPI struct {
+	root    *Command
+	program string
+	cors    string
+	stderr  io.Writer
+	config  *runConfig
+	tools   map[string]tool // by endpoint path
+}
+
+func newHTTPAPI(root *Command, program, cors string, stderr io.Writer) *httpAPI {
+	api := &httpAPI{root: root, program: program, cors: cors, stderr: stderr, config: requestConfig(root), tools: map[string]tool{}}
+	for _, t := range collectTools(root) {
+		if t.command.isPure() {
+			api.tools[t.endpoint()] = t
+		}
+	}
+	return api
+}
+
+// endpoint is the tool's URL path: its command path with / between words.
+func (t tool) endpoint() string {
+	return "/" + strings.Join(t.path, "/")
+}
+
+// httpError is the body of every failed response.
+type httpError struct {
+	Error string `json:"error"`
+	Exit  int    `json:"exit"`
+}
+
+func (a *httpAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.cors != "" {
+		w.Header().Set("Access-Control-Allow-Origin", a.cors)
+		w.Header().Set("Vary", "Origin")
+	}
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if r.URL.Path == "/openapi.json" {
+		if r.Method != http.MethodGet {
+			a.fail(w, r, http.StatusMethodNotAllowed, Errorf(KindUsage, "use GET for %s", r.URL.Path), http.MethodGet)
+			return
+		}
+		a.writeJSON(w, r, http.StatusOK, a.openAPI())
+		return
+	}
+
+	t, ok := a.tools[strings.TrimSuffix(r.URL.Path, "/")]
+	if !ok {
+		a.fail(w, r, http.StatusNotFound, a.unknownEndpoint(r.URL.Path), "")
+		return
+	}
+	if r.Method != http.MethodPost {
+		a.fail(w, r, http.StatusMethodNotAllowed, Errorf(KindUsage, "use POST for %s", r.URL.Path), http.MethodPost)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		a.fail(w, r, http.StatusBadRequest, Errorf(KindUsage, "read request body: %v", err), "")
+		return
+	}
+
+	status, result := a.call(r.Context(), t, body)
+	a.writeJSON(w, r, status, result)
+}
+
+// call runs t with the arguments in body, returning the response's status
+// and body.
+func (a *httpAPI) call(ctx context.Context, t tool, body []byte) (int, any) {
+	var arguments map[string]json.RawMessage
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &arguments); err != nil {
+			err := Errorf(KindUsage, "request body must be a JSON object of arguments: %v", err)
+			return http.StatusBadRequest, httpError{Error: err.Error(), Exit: ExitCode(err)}
+		}
+	}
+
+	result := t.call(ctx, a.root, a.program, a.config, arguments)
+	if result.Exit != ExitOK {
+		return httpStatus(kindOfExitCode(result.Exit)), httpError{Error: result.Error, Exit: result.Exit}
+	}
+	if structured, ok := result.structured(); ok {
+		return http.StatusOK, structured
+	}
+	return http.StatusOK, map[string]string{"output": strings.TrimSuffix(result.Output, "\n")}
+}
+
+func (a *httpAPI) unknownEndpoint(endpoint string) error {
+	return Errorf(KindNotFound, "unknown endpoint: %s%s", endpoint, DidYouMean(endpoint, a.endpoints()))
+}
+
+func (a *httpAPI) fail(w http.ResponseWriter, r *http.Request, status int, err error, allow string) {
+	if allow != "" {
+		w.Header().Set("Allow", allow+", OPTIONS")
+	}
+	a.writeJSON(w, r, status, httpError{Error: err.Error(), Exit: ExitCode(err)})
+}
+
+// httpStatus maps a command's error kind to the closest HTTP status.
+func httpStatus(kind ErrorKind) int {
+	switch kind {
+	case KindUsage:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindUnavailable, KindInterrupted:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (a *httpAPI) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintf(a.stderr, "Error: %s %s: %v\n", r.Method, r.URL.Path, err)
+	}
+}
+
+func (a *httpAPI) endpoints() []string {
+	endpoints := make([]string, 0, len(a.tools))
+	for endpoint := range a.tools {
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// openAPI describes every endpoint as an OpenAPI 3.1 document, taking each
+// operation's request schema and documentation from its command.
+func (a *httpAPI) openAPI() map[string]any {
+	version := "dev"
+	if a.root.Version != nil && a.root.Version.Version != "" {
+		version = a.root.Version.Version
+	}
+	errorResponse := map[string]any{
+		"description": "The command failed; exit is the code it would exit with",
+		"content":     map[string]any{"application/json": map[string]any{"schema": map[string]string{"$ref": "#/components/schemas/Error"}}},
+	}
+
+	paths := map[string]any{
+		"/openapi.json": map[string]any{"get": map[string]any{
+			"operationId": "openapi",
+			"summary":     "This document",
+			"responses":   map[string]any{"200": map[string]any{"description": "The OpenAPI document"}},
+		}},
+	}
+	for endpoint, t := range a.tools {
+		operation := map[string]any{
+			"operationId": t.Name,
+			"summary":     t.command.Description,
+			"requestBody": map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": t.inputSchema()}},
+			},
+			"responses": map[string]any{
+				"200":     map[string]any{"description": "The command's result, as it prints with --output json", "content": map[string]any{"application/json": map[string]any{"schema": map[string]any{}}}},
+				"400":     errorResponse,
+				"default": errorResponse,
+			},
+		}
+		if len(t.command.Sections) > 0 {
+			operation["description"] = sectionsText(t.command.Sections)
+		}
+		paths[endpoint] = map[string]any{"post": operation}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info":    map[string]string{"title": a.root.Name, "description": a.root.Description, "version": version},
+		"paths":   paths,
+		"components": map[string]any{"schemas": map[string]any{"Error": &jsonSchema{
+			Type: "object",
+			Properties: map[string]*jsonSchema{
+				"error": {Type: "string", Description: "What went wrong"},
+				"exit":  {Type: "integer", Description: "The exit code the command would exit with"},
+			},
+			Required: []string{"error", "exit"},
+		}}},
+	}
+}
+
+// sectionsText renders help sections as plain text, for documentation
+// outside the terminal.
+func sectionsText(sections []Section) string {
+	var b strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(section.Title + ":\n")
+		for _, line := range section.Lines {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,97 @@
+package cli_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+func newHTTPRoot() *cli.Command {
+	return cli.New("tool", "HTTP test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		Register(
+			cli.New("text", "Text tools").AsPure().Register(
+				newUpperCommand("upper").WithSections(cli.Section{Title: "NOTES", Lines: []string{"Only letters change."}}),
+			),
+			newFailCommand(),
+		)
+}
+
+// httpCase is one request to the HTTP API, compared against
+// testdata/http-<name>.golden.
+type httpCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	cors   string
+}
+
+func TestHTTPHandler(t *testing.T) {
+	const origin = "http://localhost:3000"
+	tests := []httpCase{
+		{name: "call", method: "POST", path: "/text/upper", body: `{"text": "a b"}`, cors: origin},
+		{name: "usage", method: "POST", path: "/text/upper", body: `{"text": ""}`, cors: origin},
+		{name: "unknown-argument", method: "POST", path: "/text/upper", body: `{"txt": "a"}`, cors: origin},
+		{name: "bad-body", method: "POST", path: "/text/upper", body: `{"text": `, cors: origin},
+		{name: "impure", method: "POST", path: "/fail", body: `{}`, cors: origin},
+		{name: "typo", method: "POST", path: "/text/uper", body: `{}`, cors: origin},
+		{name: "method", method: "GET", path: "/text/upper", cors: origin},
+		{name: "preflight", method: "OPTIONS", path: "/text/upper", cors: origin},
+		{name: "no-cors", method: "POST", path: "/text/upper", body: `{"text": "a"}`},
+		{name: "any-origin", method: "POST", path: "/text/upper", body: `{"text": "a"}`, cors: "*"},
+		{name: "openapi", method: "GET", path: "/openapi.json", cors: origin},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(cli.HTTPHandler(newHTTPRoot(), "tool", tc.cors))
+			defer srv.Close()
+
+			req, err := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.method == http.MethodOptions {
+				req.Header.Set("Origin", "http://localhost:3000")
+				req.Header.Set("Access-Control-Request-Method", "POST")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var b strings.Builder
+			b.WriteString(strings.TrimSpace(tc.method+" "+tc.path+" "+tc.body) + "\n")
+			if tc.cors != "" {
+				b.WriteString("cors " + tc.cors + "\n")
+			}
+			b.WriteString("-- response --\n" + resp.Status + "\n")
+			var headers []string
+			for name := range resp.Header {
+				if name != "Date" && name != "Content-Length" {
+					headers = append(headers, name+": "+resp.Header.Get(name))
+				}
+			}
+			slices.Sort(headers)
+			for _, header := range headers {
+				b.WriteString(header + "\n")
+			}
+			if len(body) > 0 {
+				b.WriteString("\n" + string(body))
+			}
+			clitest.Golden(t, "testdata/http-"+tc.name+".golden", b.String())
+		})
+	}
+}
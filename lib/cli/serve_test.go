@@ -0,0 +1,98 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khinshankhan/yui/lib/cli"
+	"github.com/khinshankhan/yui/lib/cli/clitest"
+)
+
+// newServeRoot has upper twice, as text upper and as a shortcut at the
+// root, to check that a shortcut is served once.
+func newServeRoot() *cli.Command {
+	return cli.New("tool", "Serve test tool").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		Register(
+			newUpperCommand("upper"),
+			cli.New("text", "Text tools").Register(newUpperCommand("upper")),
+			cli.New("greet", "Greet someone").
+				WithArgs(cli.RequiredArg("name")).
+				RegisterFlags(
+					cli.BoolFlag("shout", "s", "Greet loudly"),
+					cli.StringFlag("title", "t", "title", "Titles to greet with").Repeated(),
+				).
+				WithRun(func(ctx *cli.Context, args []string) error {
+					greeting := "hello " + strings.Join(append(ctx.Strings("title"), ctx.Arg("name")), " ")
+					if ctx.Bool("shout") {
+						greeting = strings.ToUpper(greeting)
+					}
+					return ctx.Render(map[string]string{"greeting": greeting})
+				}),
+			cli.New("wipe", "Wipe the disk").AsUnserved().WithRun(noop),
+			newFailCommand(),
+			cli.NewServeCommand("serve"),
+		)
+}
+
+func TestServeStdio(t *testing.T) {
+	request := func(name, line string) clitest.Case {
+		return clitest.Case{Name: "serve-" + name, Args: []string{"serve", "--stdio"}, Stdin: line + "\n"}
+	}
+	clitest.RunGolden(t, newServeRoot(),
+		request("initialize", `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26"}}`),
+		request("notification", `{"jsonrpc": "2.0", "method": "notifications/initialized"}`),
+		request("tools-list", `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`),
+		request("call", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "text_upper", "arguments": {"text": "a b"}}}`),
+		request("call-structured", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "greet", "arguments": {"name": "ada", "shout": true, "title": ["dr", "prof"]}}}`),
+		request("call-failed", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "fail", "arguments": {}}}`),
+		request("call-unknown-argument", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "greet", "arguments": {"nam": "ada"}}}`),
+		request("call-unknown-tool", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "gret"}}`),
+		request("call-unserved", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "wipe"}}`),
+		request("call-shortcut", `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "upper", "arguments": {"text": "a"}}}`),
+		request("method", `{"jsonrpc": "2.0", "id": 1, "method": "greet", "params": {"name": "-x"}}`),
+		request("method-failed", `{"jsonrpc": "2.0", "id": 1, "method": "fail"}`),
+		request("method-utility", `{"jsonrpc": "2.0", "id": 1, "method": "serve"}`),
+		request("parse-error", `not json`),
+		request("invalid-request", `{"jsonrpc": 2, "id": 1, "method": "ping"}`),
+		request("batch-request", `[{"jsonrpc": "2.0", "id": 1, "method": "ping"}]`),
+		request("batch-empty", `[]`),
+		request("batch-truncated", `[{"jsonrpc": "2.0", "id": 1,`),
+	)
+}
+
+func TestServeStdioInterrupted(t *testing.T) {
+	checkInterrupted(t, newServeRoot(), "serve", "--stdio")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestServeStdioWriteFailure(t *testing.T) {
+	in, input := io.Pipe()
+	defer input.Close()
+	go input.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}` + "\n"))
+
+	var stderr bytes.Buffer
+	done := make(chan int, 1)
+	go func() {
+		done <- cli.ExecuteWith(newServeRoot(), []string{"serve", "--stdio"}, cli.Options{Stdin: in, Stdout: failingWriter{}, Stderr: &stderr})
+	}()
+
+	// stdin stays open, so only the failed write can end the loop.
+	select {
+	case code := <-done:
+		if code != cli.ExitFailure || !strings.Contains(stderr.String(), "write response: broken pipe") {
+			t.Fatalf("serve --stdio = %d, want %d; stderr = %s", code, cli.ExitFailure, stderr.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve --stdio still reading after a failed write")
+	}
+}
@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// historySize is how many history lines the shell loads at startup.
+const historySize = 1000
+
+// NewShellCommand returns a command that reads command lines, interactively
+// or from stdin, and runs them against the program's command tree without
+// starting a new process for each.
+func NewShellCommand(name string) *Command {
+	return New(name, "Run commands interactively").
+		RegisterFlags(StringFlag("history", "", "file", "Keep history in file instead of the state directory; empty disables it")).
+		WithSections(Section{
+			Title: "SHELL",
+			Lines: []string{
+				"Lines are split like a POSIX shell would, honoring '...' and \"...\" quotes.",
+				"$_ expands to the output of the last command that succeeded.",
+				"Tab completes commands, flags and values; Up and Down walk the history.",
+				"History is kept in $XDG_STATE_HOME or ~/.local/state unless --history is given.",
+				"exit, quit or Ctrl-D leaves the shell.",
+			},
+		}).
+		WithExampleSpecs(
+			Example{Note: "Start an interactive session", External: true},
+			Example{Stdin: `case kebab "Hello World"`, Output: "hello-world"},
+		).
+		WithRun(runShell).
+		AsUtility()
+}
+
+type shell struct {
+	root    *Command
+	program string
+	ctx     context.Context
+	stdout  io.Writer
+	stderr  io.Writer
+	vars    map[string]string
+	macros  []Macro // config aliases, for completion
+}
+
+func runShell(ctx *Context, args []string) error {
+	s := &shell{
+		root:    ctx.Root,
+		program: ctx.Path[0],
+		ctx:     context.WithoutCancel(ctx.Context()),
+		stdout:  ctx.Stdout,
+		stderr:  ctx.Stderr,
+		vars:    map[string]string{"_": ""},
+		macros:  ctx.configMacros,
+	}
+
+	// Only a terminal can be put into raw mode.
+	if file, ok := ctx.Stdin.(*os.File); ok {
+		if state, err := makeRaw(file.Fd()); err == nil {
+			restoreTerminal(file.Fd(), state)
+			return s.interactive(ctx, file)
+		}
+	}
+
+	failed := 0
+	scanner := bufio.NewScanner(ctx.Stdin)
+	for scanner.Scan() {
+		code, exit := s.run(scanner.Text())
+		if exit {
+			break
+		}
+		if code != ExitOK {
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return Errorf(KindFailure, "%d command(s) failed", failed)
+	}
+	return nil
+}
+
+// interactive edits lines on the terminal in raw mode, switching back to
+// the terminal's own settings while each command runs.
+func (s *shell) interactive(ctx *Context, term *os.File) error {
+	history, err := shellHistoryPath(ctx)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "warning: history: %v\n", err)
+	}
+	editor := &lineEditor{
+		in:       bufio.NewReader(term),
+		out:      s.stdout,
+		prompt:   s.program + "> ",
+		complete: s.complete,
+	}
+	if history != "" {
+		if editor.history, err = loadHistory(history); err != nil {
+			fmt.Fprintf(s.stderr, "warning: history: %v\n", err)
+			history = ""
+		}
+	}
+
+	for {
+		state, err := makeRaw(term.Fd())
+		if err != nil {
+			return err
+		}
+		line, err := editor.readLine()
+		restoreTerminal(term.Fd(), state)
+		if errors.Is(err, errLineCancelled) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, " ") &&
+			(len(editor.history) == 0 || editor.history[len(editor.history)-1] != line) {
+			editor.history = append(editor.history, line)
+			if history != "" {
+				if err := appendHistory(history, line); err != nil {
+					fmt.Fprintf(s.stderr, "warning: history: %v\n", err)
+					history = ""
+				}
+			}
+		}
+
+		if _, exit := s.run(line); exit {
+			return nil
+		}
+	}
+}
+
+// run executes one command line, reporting its exit code and whether it
+// asked to leave the shell.
+func (s *shell) run(line string) (int, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ExitOK, false
+	}
+	args, err := splitArgs(line, s.vars)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "Error: %v\n", err)
+		return ExitUsage, false
+	}
+	args = s.trimProgram(args)
+	if len(args) == 1 && (args[0] == "exit" || args[0] == "quit") && s.root.findSubcommand(args[0]) == nil {
+		return ExitOK, true
+	}
+
+	// Ctrl-C stops the running command, not the shell.
+	ctx, stop := signal.NotifyContext(s.ctx, os.Interrupt)
+	defer stop()
+
+	var out bytes.Buffer
+	code := ExecuteWith(s.root, args, Options{
+		Context: ctx,
+		Program: s.program,
+		Stdout:  io.MultiWriter(s.stdout, &out),
+		Stderr:  s.stderr,
+	})
+	if code == ExitOK {
+		s.vars["_"] = strings.TrimSuffix(out.String(), "\n")
+	}
+	return code, false
+}
+
+// trimProgram drops a leading program name, so lines pasted from a
+// terminal run as they would there.
+func (s *shell) trimProgram(args []string) []string {
+	if len(args) > 0 && args[0] == s.program && s.root.findSubcommand(args[0]) == nil {
+		return args[1:]
+	}
+	return args
+}
+
+// complete offers the tree's completions for the word before pos, quoted
+// for the shell, falling back to file names.
+func (s *shell) complete(line []rune, pos int) (int, []string) {
+	start := wordStart(line[:pos])
+	words, err := SplitArgs(string(line[:start]))
+	if err != nil {
+		return pos, nil
+	}
+	word := strings.TrimLeft(string(line[start:pos]), `'"`)
+
+	candidates, directive := complete(s.root, append(s.trimProgram(words), word), s.macros)
+	var out []string
+	for _, c := range candidates {
+		out = append(out, quoteArg(c.value))
+	}
+	if directive == CompleteFiles || (directive == CompleteDefault && len(out) == 0) {
+		out = append(out, fileCandidates(word)...)
+	}
+	return start, out
+}
+
+// wordStart returns where the word being typed at the end of line begins.
+func wordStart(line []rune) int {
+	start := 0
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\\':
+			escaped = true
+		case r == '\'' || r == '"':
+			quote = r
+		case unicode.IsSpace(r):
+			start = i + 1
+		}
+	}
+	return start
+}
+
+// fileCandidates returns the paths starting with prefix, directories
+// ending in a slash.
+func fileCandidates(prefix string) []string {
+	if strings.ContainsAny(prefix, `*?[\`) {
+		return nil
+	}
+	matches, _ := filepath.Glob(prefix + "*")
+	var out []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			match += string(filepath.Separator)
+		}
+		out = append(out, quoteArg(match))
+	}
+	return out
+}
+
+func shellHistoryPath(ctx *Context) (string, error) {
+	if file := ctx.String("history"); file != "" || ctx.IsSet("history") {
+		return file, nil
+	}
+	dir, err := userStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ctx.Root.Name, "history"), nil
+}
+
+// userStateDir is userConfigDir for state, such as history, that is not
+// worth backing up.
+func userStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return os.UserConfigDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// loadHistory returns the last historySize lines of the history file.
+func loadHistory(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || len(b) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) > historySize {
+		lines = lines[len(lines)-historySize:]
+	}
+	return lines, nil
+}
+
+func appendHistory(path, line string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,47 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestShellScript(t *testing.T) {
+	echo := func(ctx *cli.Context, args []string) error {
+		return ctx.Render(strings.Join(args, "|"))
+	}
+	root := cli.New("tool", "Shell test tool").
+		Register(
+			cli.New("echo", "Echo words").WithArgs(cli.VariadicArg("words")).WithRun(echo),
+			cli.NewShellCommand("shell"),
+		)
+
+	tests := []struct {
+		name   string
+		script string
+		want   string
+		code   int
+	}{
+		{name: "quoting", script: "echo 'a b' \"c d\" e\\ f\n", want: "a b|c d|e f\n"},
+		{name: "previous output", script: "echo one two\necho \"$_\" three\necho '$_' \\$_\n", want: "one|two\none|two|three\n$_|$_\n"},
+		{name: "program name and comments", script: "# note\n\ntool echo x\n", want: "x\n"},
+		{name: "exit", script: "echo x\nexit\necho y\n", want: "x\n"},
+		{name: "failures", script: "nope\necho x\n\"open\n", want: "x\n", code: cli.ExitFailure},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := cli.ExecuteWith(root, []string{"shell"}, cli.Options{
+				Stdin:  strings.NewReader(tc.script),
+				Stdout: &stdout,
+				Stderr: &stderr,
+			})
+			if code != tc.code || stdout.String() != tc.want {
+				t.Fatalf("shell = %d %q, want %d %q; stderr = %s", code, stdout.String(), tc.code, tc.want, stderr.String())
+			}
+		})
+	}
+}
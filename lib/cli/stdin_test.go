@@ -0,0 +1,107 @@
+package cli_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func newStdinCommand(out *string) *cli.Command {
+	return cli.New("tool", "Stdin test tool").
+		WithArgs(cli.OptionalArg("mode"), cli.RequiredArg("text").FromStdin()).
+		WithRun(func(ctx *cli.Context, args []string) error {
+			text, err := ctx.TrimmedInput("text")
+			if err != nil {
+				return err
+			}
+			*out = "mode=" + ctx.Arg("mode") + " text=" + text
+			return nil
+		})
+}
+
+func TestExecuteWithStdin(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   io.Reader
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "piped input fills stdin arg",
+			stdin: strings.NewReader("Hello World\n"),
+			args:  []string{"kebab"},
+			want:  "mode=kebab text=Hello World",
+		},
+		{
+			name:  "positional text wins over piped input",
+			stdin: strings.NewReader("Hello World\n"),
+			args:  []string{"kebab", "Hi"},
+			want:  "mode=kebab text=Hi",
+		},
+		{
+			name: "nil stdin falls back to args",
+			args: []string{"kebab", "Hi"},
+			want: "mode=kebab text=Hi",
+		},
+		{
+			name:    "blank piped input is missing",
+			stdin:   strings.NewReader("  \n"),
+			wantErr: "text required via argument or stdin",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			var stdout, stderr bytes.Buffer
+			code := cli.ExecuteWith(newStdinCommand(&got), tc.args, cli.Options{
+				Stdin:  tc.stdin,
+				Stdout: &stdout,
+				Stderr: &stderr,
+			})
+
+			if tc.wantErr != "" {
+				if code == 0 {
+					t.Fatalf("ExecuteWith() code = 0, want failure")
+				}
+				if !strings.Contains(stderr.String(), tc.wantErr) {
+					t.Fatalf("stderr = %q, want error containing %q", stderr.String(), tc.wantErr)
+				}
+				return
+			}
+			if code != 0 {
+				t.Fatalf("ExecuteWith() code = %d, stderr = %s", code, stderr.String())
+			}
+			if got != tc.want {
+				t.Fatalf("ExecuteWith(%q) = %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInputKeepsRawStdin(t *testing.T) {
+	var got string
+	cmd := cli.New("copy", "Copy").
+		WithArgs(cli.VariadicArg("text").FromStdin()).
+		WithRun(func(ctx *cli.Context, args []string) error {
+			if !ctx.Piped() {
+				t.Fatalf("Piped() = false, want true")
+			}
+			var err error
+			got, err = ctx.Input("text")
+			return err
+		})
+
+	var stdout, stderr bytes.Buffer
+	code := cli.ExecuteWith(cmd, nil, cli.Options{Stdin: strings.NewReader("line\n"), Stdout: &stdout, Stderr: &stderr})
+	if code != 0 {
+		t.Fatalf("ExecuteWith() code = %d, stderr = %s", code, stderr.String())
+	}
+	if got != "line\n" {
+		t.Fatalf("Input() = %q, want %q", got, "line\n")
+	}
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithPrefixMatching lets a unique prefix of a subcommand name or alias select
+// it, for this command and every command below it. Exact matches always win.
+func (c *Command) WithPrefixMatching() *Command {
+	c.PrefixMatching = true
+	return c
+}
+
+func (c *Command) prefixMatching() bool {
+	for p := c; p != nil; p = p.parent {
+		if p.PrefixMatching {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSubcommand resolves token to a subcommand, falling back to a unique
+// prefix when prefix matching is on. It returns nil when nothing matches and
+// an error when the prefix is shared by several subcommands.
+func (c *Command) matchSubcommand(token string) (*Command, error) {
+	if sub := c.findSubcommand(token); sub != nil {
+		return sub, nil
+	}
+	if token == "" || !c.prefixMatching() {
+		return nil, nil
+	}
+
+	token = strings.ToLower(token)
+	var matches []*Command
+	for _, sub := range c.Subcommands {
+		if sub.Hidden {
+			continue
+		}
+		for _, name := range append([]string{sub.Name}, sub.Aliases...) {
+			if strings.HasPrefix(strings.ToLower(name), token) {
+				matches = append(matches, sub)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, match := range matches {
+			names[i] = match.Name
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("ambiguous command %q: could be %s", token, strings.Join(names, ", "))
+	}
+}
+
+// suggestSubcommands returns the visible subcommand names closest to token,
+// comparing against aliases too.
+func (c *Command) suggestSubcommands(token string) []string {
+	best := make(map[string]int)
+	for _, sub := range c.Subcommands {
+		if sub.Hidden {
+			continue
+		}
+		for _, name := range append([]string{sub.Name}, sub.Aliases...) {
+			if d, ok := suggestDistance(token, name); ok {
+				if prev, seen := best[sub.Name]; !seen || d < prev {
+					best[sub.Name] = d
+				}
+			}
+		}
+	}
+	return rankSuggestions(best)
+}
+
+// Suggest returns the candidates closest to token when they are near enough
+// to be a likely typo. Candidates that start with token count as closest.
+func Suggest(token string, candidates []string) []string {
+	best := make(map[string]int)
+	for _, candidate := range candidates {
+		if d, ok := suggestDistance(token, candidate); ok {
+			if prev, seen := best[candidate]; !seen || d < prev {
+				best[candidate] = d
+			}
+		}
+	}
+	return rankSuggestions(best)
+}
+
+// DidYouMean formats Suggest's result for appending to an error message,
+// e.g. ` (did you mean "paste"?)`. It returns "" when nothing is close.
+func DidYouMean(token string, candidates []string) string {
+	return didYouMean(Suggest(token, candidates))
+}
+
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	if len(suggestions) > 3 {
+		suggestions = suggestions[:3]
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf(" (did you mean %s?)", quoted[0])
+	}
+	return fmt.Sprintf(" (did you mean %s or %s?)", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+// rankSuggestions keeps only the closest candidates, sorted by name.
+func rankSuggestions(best map[string]int) []string {
+	closest := -1
+	for _, d := range best {
+		if closest < 0 || d < closest {
+			closest = d
+		}
+	}
+
+	out := make([]string, 0, len(best))
+	for name, d := range best {
+		if d == closest {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// suggestDistance scores candidate against token. Candidates that start with
+// token score 0; others are kept when within a small edit distance.
+func suggestDistance(token, candidate string) (int, bool) {
+	token = strings.ToLower(token)
+	candidate = strings.ToLower(candidate)
+	if token == "" || token == candidate {
+		return 0, false
+	}
+	if len(token) >= 2 && strings.HasPrefix(candidate, token) {
+		return 0, true
+	}
+
+	limit := 2
+	if len(token) <= 3 {
+		limit = 1
+	}
+	d := editDistance(token, candidate)
+	return d, d <= limit
+}
+
+// editDistance is the optimal string alignment distance: insertions,
+// deletions, substitutions and adjacent transpositions each cost one.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,127 @@
+package cli_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"hex", "rgb", "hsl", "hsv", "kebab", "snake", "paste", "path"}
+	tests := []struct {
+		token string
+		want  []string
+	}{
+		{token: "rbg", want: []string{"rgb"}},
+		{token: "hls", want: []string{"hsl"}},
+		{token: "kebap", want: []string{"kebab"}},
+		{token: "pasta", want: []string{"paste"}},
+		{token: "pa", want: []string{"paste", "path"}},
+		{token: "zzz", want: []string{}},
+		{token: "hex", want: []string{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.token, func(t *testing.T) {
+			if got := cli.Suggest(tc.token, candidates); !slices.Equal(got, tc.want) {
+				t.Fatalf("Suggest(%q) = %q, want %q", tc.token, got, tc.want)
+			}
+		})
+	}
+}
+
+func newSuggestTree(prefix bool, out *string) *cli.Command {
+	leaf := func(name string) *cli.Command {
+		return cli.New(name, name).WithRun(func(ctx *cli.Context, args []string) error {
+			*out = strings.Join(ctx.Path, " ")
+			return nil
+		})
+	}
+
+	root := cli.New("tool", "Suggest test tool").
+		Register(
+			leaf("copy"),
+			leaf("paste"),
+			leaf("color").WithAliases("col"),
+			cli.New("net", "Network").Register(
+				cli.New("ip", "IP").Register(leaf("all"), leaf("primary")),
+			),
+		)
+	if prefix {
+		root.WithPrefixMatching()
+	}
+	return root
+}
+
+func TestExecuteSuggestsCommands(t *testing.T) {
+	var got string
+	var stdout, stderr bytes.Buffer
+	code := cli.Execute(newSuggestTree(false, &got), []string{"pasta"}, &stdout, &stderr)
+	if code == 0 {
+		t.Fatalf("Execute() code = 0, want failure")
+	}
+	if want := `unknown command: pasta (did you mean "paste"?)`; !strings.Contains(stderr.String(), want) {
+		t.Fatalf("stderr = %q, want %q", stderr.String(), want)
+	}
+	if strings.Contains(stderr.String(), "USAGE:") {
+		t.Fatalf("stderr prints full help alongside a suggestion:\n%s", stderr.String())
+	}
+}
+
+func TestExecutePrefixMatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  bool
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "unique prefix", prefix: true, args: []string{"pas"}, want: "tool paste"},
+		{name: "nested prefixes", prefix: true, args: []string{"ne", "i", "al"}, want: "tool net ip all"},
+		{name: "exact alias wins", prefix: true, args: []string{"col"}, want: "tool color"},
+		{name: "ambiguous prefix", prefix: true, args: []string{"co"}, wantErr: `ambiguous command "co": could be color, copy`},
+		{name: "off by default", args: []string{"pas"}, wantErr: `unknown command: pas (did you mean "paste"?)`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			var stdout, stderr bytes.Buffer
+			code := cli.Execute(newSuggestTree(tc.prefix, &got), tc.args, &stdout, &stderr)
+			if tc.wantErr != "" {
+				if code == 0 || !strings.Contains(stderr.String(), tc.wantErr) {
+					t.Fatalf("Execute(%q) code = %d, stderr = %q, want error %q", tc.args, code, stderr.String(), tc.wantErr)
+				}
+				return
+			}
+			if code != 0 {
+				t.Fatalf("Execute(%q) code = %d, stderr = %s", tc.args, code, stderr.String())
+			}
+			if got != tc.want {
+				t.Fatalf("Execute(%q) ran %q, want %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidatePrefixMatching(t *testing.T) {
+	var got string
+	root := newSuggestTree(true, &got).WithExample("Copy", "co")
+	err := cli.Validate(root)
+	if err == nil || !strings.Contains(err.Error(), `ambiguous command "co"`) {
+		t.Fatalf("Validate() = %v, want ambiguous example error", err)
+	}
+
+	root = cli.New("tool", "Tool").
+		WithPrefixMatching().
+		WithArgs(cli.OptionalArg("text")).
+		Register(cli.New("list", "List").WithRun(noop)).
+		WithRun(noop)
+	err = cli.Validate(root)
+	if err == nil || !strings.Contains(err.Error(), "an argument could select a subcommand") {
+		t.Fatalf("Validate() = %v, want args alongside prefix matching error", err)
+	}
+}
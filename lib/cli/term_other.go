@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+
+package cli
+
+import "errors"
+
+// terminalState is unused where raw mode is unsupported; the shell reads
+// whole lines instead.
+type terminalState struct{}
+
+func makeRaw(fd uintptr) (*terminalState, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func restoreTerminal(fd uintptr, state *terminalState) error {
+	return nil
+}
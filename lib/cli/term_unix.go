@@ -0,0 +1,45 @@
+//go:build linux || darwin
+
+package cli
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalState is a terminal's settings before makeRaw changed them.
+type terminalState struct {
+	termios syscall.Termios
+}
+
+// makeRaw puts the terminal on fd into raw mode, so keys arrive one at a
+// time without echo, and returns the settings to restore. It fails when fd
+// is not a terminal.
+func makeRaw(fd uintptr) (*terminalState, error) {
+	var state terminalState
+	if err := ioctlTermios(fd, ioctlGetTermios, &state.termios); err != nil {
+		return nil, err
+	}
+
+	raw := state.termios
+	raw.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctlTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func restoreTerminal(fd uintptr, state *terminalState) error {
+	return ioctlTermios(fd, ioctlSetTermios, &state.termios)
+}
+
+func ioctlTermios(fd, request uintptr, termios *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,495 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlDoc is the subset of TOML that config files use: tables of keys whose
+// values are strings, numbers, booleans or arrays of them. Entries remember
+// the lines they came from so set can rewrite a file in place, keeping its
+// comments and layout.
+type tomlDoc struct {
+	lines  []string
+	tables []*tomlTable
+}
+
+type tomlTable struct {
+	name    string // dotted table name, "" for keys before any header
+	header  int    // line of the [header], -1 for the top level
+	entries []*tomlEntry
+}
+
+type tomlEntry struct {
+	key    string // dotted when written as a.b = ..., relative to its table
+	values []string
+	first  int
+	last   int
+}
+
+// table returns the named table, or nil.
+func (d *tomlDoc) table(name string) *tomlTable {
+	for _, t := range d.tables {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// find returns the entry for key in the named table, written either under
+// the table's header or as a dotted key in a table above it, along with the
+// table it was written in.
+func (d *tomlDoc) find(table, key string) (*tomlTable, *tomlEntry) {
+	full := joinTOMLKey(table, key)
+	for _, t := range d.tables {
+		for _, e := range t.entries {
+			if joinTOMLKey(t.name, e.key) == full {
+				return t, e
+			}
+		}
+	}
+	return nil, nil
+}
+
+// lookup returns the values of key in the named table.
+func (d *tomlDoc) lookup(table, key string) ([]string, bool) {
+	_, e := d.find(table, key)
+	if e == nil {
+		return nil, false
+	}
+	return e.values, true
+}
+
+// entries returns the keys set in the named table, wherever they are
+// written, mapped to their values.
+func (d *tomlDoc) entries(table string) ([]string, map[string][]string) {
+	var keys []string
+	values := make(map[string][]string)
+	for _, t := range d.tables {
+		for _, e := range t.entries {
+			key, ok := strings.CutPrefix(joinTOMLKey(t.name, e.key), table+".")
+			if !ok || strings.Contains(key, ".") {
+				continue
+			}
+			keys = append(keys, key)
+			values[key] = e.values
+		}
+	}
+	return keys, values
+}
+
+func joinTOMLKey(table, key string) string {
+	if table == "" {
+		return key
+	}
+	return table + "." + key
+}
+
+// set replaces key in the named table with a "key = value" line, adding the
+// key or the table when they do not exist yet.
+func (d *tomlDoc) set(table, key, value string) {
+	line := tomlKey(key) + " = " + value
+	if _, e := d.find(table, key); e != nil {
+		d.splice(e.first, e.last+1, tomlTableName(e.key)+" = "+value)
+		return
+	}
+	t := d.table(table)
+	switch {
+	case t != nil && len(t.entries) > 0:
+		d.splice(t.entries[len(t.entries)-1].last+1, t.entries[len(t.entries)-1].last+1, line)
+	case t != nil && t.header >= 0:
+		d.splice(t.header+1, t.header+1, line)
+	case table == "":
+		lines := []string{line}
+		if len(d.lines) > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[0]), "[") {
+			lines = append(lines, "")
+		}
+		d.splice(0, 0, lines...)
+	default:
+		lines := []string{"[" + tomlTableName(table) + "]", line}
+		if n := len(d.lines); n > 0 && strings.TrimSpace(d.lines[n-1]) != "" {
+			lines = append([]string{""}, lines...)
+		}
+		d.splice(len(d.lines), len(d.lines), lines...)
+	}
+}
+
+// splice replaces lines[from:to] and reparses, keeping positions current.
+func (d *tomlDoc) splice(from, to int, lines ...string) {
+	d.lines = append(d.lines[:from:from], append(lines, d.lines[to:]...)...)
+	if doc, err := parseTOML(d.String()); err == nil {
+		*d = *doc
+	}
+}
+
+func (d *tomlDoc) String() string {
+	if len(d.lines) == 0 {
+		return ""
+	}
+	return strings.Join(d.lines, "\n") + "\n"
+}
+
+func parseTOML(src string) (*tomlDoc, error) {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	doc := &tomlDoc{lines: strings.Split(strings.TrimSuffix(src, "\n"), "\n")}
+	if src == "" {
+		doc.lines = nil
+	}
+
+	current := &tomlTable{name: "", header: -1}
+	doc.tables = append(doc.tables, current)
+
+	p := &tomlParser{src: src}
+	for {
+		p.skipBlank()
+		if p.done() {
+			return doc, nil
+		}
+
+		line := p.line
+		if p.peek() == '[' {
+			p.pos++
+			name, err := p.dottedKey(']')
+			if err != nil {
+				return nil, p.errorf(line, "%v", err)
+			}
+			p.pos++
+			if err := p.endOfLine(); err != nil {
+				return nil, p.errorf(line, "%v", err)
+			}
+			if doc.table(name) != nil {
+				return nil, p.errorf(line, "table [%s] defined twice", name)
+			}
+			current = &tomlTable{name: name, header: line}
+			doc.tables = append(doc.tables, current)
+			continue
+		}
+
+		key, err := p.dottedKey('=')
+		if err != nil {
+			return nil, p.errorf(line, "%v", err)
+		}
+		p.pos++
+		p.skipSpace()
+		values, err := p.value()
+		if err != nil {
+			return nil, p.errorf(line, "%s: %v", key, err)
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, p.errorf(line, "%v", err)
+		}
+		if _, e := doc.find(current.name, key); e != nil {
+			return nil, p.errorf(line, "%s defined twice", joinTOMLKey(current.name, key))
+		}
+		current.entries = append(current.entries, &tomlEntry{key: key, values: values, first: line, last: p.line - 1})
+	}
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) errorf(line int, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", line+1, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) skipSpace() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	if p.peek() == '#' {
+		for !p.done() && p.peek() != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank skips whitespace, comments and newlines.
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		if p.peek() != '\n' {
+			return
+		}
+		p.pos++
+		p.line++
+	}
+}
+
+// endOfLine consumes trailing space, a comment and the newline.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	p.skipComment()
+	if p.done() {
+		p.line++
+		return nil
+	}
+	if p.peek() != '\n' {
+		return fmt.Errorf("unexpected %q", p.src[p.pos:p.lineEnd()])
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+func (p *tomlParser) lineEnd() int {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		return p.pos + i
+	}
+	return len(p.src)
+}
+
+func (p *tomlParser) key() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.basicString()
+	case '\'':
+		return p.literalString()
+	}
+	start := p.pos
+	for isBareKeyByte(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected a key, found %q", p.src[p.pos:p.lineEnd()])
+	}
+	return p.src[start:p.pos], nil
+}
+
+// dottedKey reads a table name or key up to end, joining its parts with
+// dots.
+func (p *tomlParser) dottedKey(end byte) (string, error) {
+	var parts []string
+	for {
+		p.skipSpace()
+		part, err := p.key()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+		p.skipSpace()
+		switch p.peek() {
+		case '.':
+			p.pos++
+		case end:
+			return strings.Join(parts, "."), nil
+		default:
+			return "", fmt.Errorf("expected %q after %s", end, strings.Join(parts, "."))
+		}
+	}
+}
+
+// value reads a scalar or an array of scalars, returning each as a string.
+func (p *tomlParser) value() ([]string, error) {
+	if p.peek() != '[' {
+		v, err := p.scalar()
+		if err != nil {
+			return nil, err
+		}
+		return []string{v}, nil
+	}
+
+	p.pos++
+	values := []string{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return values, nil
+		}
+		if p.done() {
+			return nil, errors.New("unterminated array")
+		}
+		v, err := p.scalar()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, errors.New("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) scalar() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.basicString()
+	case '\'':
+		return p.literalString()
+	case '[':
+		return "", errors.New("nested arrays are not supported")
+	}
+
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(" \t\n#,]", rune(p.peek())) {
+		p.pos++
+	}
+	raw := p.src[start:p.pos]
+	switch raw {
+	case "":
+		return "", errors.New("missing value")
+	case "true", "false":
+		return raw, nil
+	}
+	number := strings.ReplaceAll(raw, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err == nil {
+		return number, nil
+	}
+	if _, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return number, nil
+	}
+	return "", fmt.Errorf("invalid value %s; quote strings", raw)
+}
+
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	start := p.pos
+	for p.peek() != '\'' {
+		if p.done() || p.peek() == '\n' {
+			return "", errors.New("unterminated string")
+		}
+		p.pos++
+	}
+	p.pos++
+	return p.src[start : p.pos-1], nil
+}
+
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.done() || p.peek() == '\n' {
+			return "", errors.New("unterminated string")
+		}
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (p *tomlParser) escape() (rune, error) {
+	if p.done() {
+		return 0, errors.New("unterminated string")
+	}
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		return '\b', nil
+	case 't':
+		return '\t', nil
+	case 'n':
+		return '\n', nil
+	case 'f':
+		return '\f', nil
+	case 'r':
+		return '\r', nil
+	case '"', '\\':
+		return rune(c), nil
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return 0, errors.New("short unicode escape")
+		}
+		v, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(v)) {
+			return 0, fmt.Errorf("invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+n])
+		}
+		p.pos += n
+		return rune(v), nil
+	default:
+		return 0, fmt.Errorf("invalid escape \\%c", c)
+	}
+}
+
+func isBareKeyByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// tomlKey returns key bare when TOML allows it and quoted otherwise.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for i := 0; i < len(key); i++ {
+		if !isBareKeyByte(key[i]) {
+			return tomlString(key)
+		}
+	}
+	return key
+}
+
+func tomlTableName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = tomlKey(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tool is a runnable command offered to programs by serve, taking its
+// arguments and flags as one JSON object described by inputSchema.
+type tool struct {
+	Name    string
+	command *Command
+	path    []string // command path below the root
+	flags   []Flag
+}
+
+// jsonSchema is the subset of JSON Schema that describes tool input.
+type jsonSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// AsUnserved keeps a command, and the commands below it, from being served
+// as a tool, for commands a program should not run on the user's behalf.
+func (c *Command) AsUnserved() *Command {
+	c.Unserved = true
+	return c
+}
+
+// collectTools returns a tool for every visible command in root's tree that
+// runs, leaving out utilities, which manage the program rather than do work,
+// and unserved commands. A command that shares its Run with one deeper in
+// the tree, such as a shortcut at the root, is left to the deeper one.
+func collectTools(root *Command) []tool {
+	var tools []tool
+	var walk func(c *Command, path []string, inherited []Flag)
+	walk = func(c *Command, path []string, inherited []Flag) {
+		if c.Hidden || c.Utility || c.Unserved {
+			return
+		}
+		if c.Run != nil {
+			name := strings.Join(path, "_")
+			if name == "" {
+				name = root.Name
+			}
+			var flags []Flag
+			for _, flag := range append(append([]Flag{}, inherited...), c.ownFlags()...) {
+				if !slices.Contains(recordFlagNames, flag.Name) {
+					flags = append(flags, flag)
+				}
+			}
+			tools = append(tools, tool{Name: name, command: c, path: path, flags: flags})
+		}
+		// The root's persistent flags shape output, which serve chooses.
+		if c != root {
+			inherited = append(append([]Flag{}, inherited...), c.PersistentFlags...)
+		}
+		for _, sub := range c.Subcommands {
+			walk(sub, append(append([]string{}, path...), sub.Name), inherited)
+		}
+	}
+	walk(root, nil, nil)
+
+	deepest := make(map[uintptr]int)
+	for _, t := range tools {
+		run := reflect.ValueOf(t.command.Run).Pointer()
+		deepest[run] = max(deepest[run], len(t.path))
+	}
+	return slices.DeleteFunc(tools, func(t tool) bool {
+		return len(t.path) < deepest[reflect.ValueOf(t.command.Run).Pointer()]
+	})
+}
+
+func findTool(tools []tool, name string) (tool, bool) {
+	for _, t := range tools {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+// inputSchema describes the tool's arguments, then its flags, as properties
+// of one object. Arguments read from stdin take the whole text as a string.
+func (t tool) inputSchema() *jsonSchema {
+	closed := false
+	schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: &closed}
+	for _, arg := range t.command.Args {
+		prop := &jsonSchema{Type: "string", Description: "The " + arg.Name + " argument"}
+		if arg.Stdin {
+			prop.Description = "The " + arg.Name + ", as the command would read it from stdin"
+		} else if arg.Variadic {
+			prop = &jsonSchema{Type: "array", Description: "The " + arg.Name + " arguments", Items: &jsonSchema{Type: "string"}}
+		}
+		schema.Properties[arg.Name] = prop
+		if arg.required() {
+			schema.Required = append(schema.Required, arg.Name)
+		}
+	}
+	for _, flag := range t.flags {
+		prop := &jsonSchema{Type: flagSchemaType(flag), Description: formatFlagDescription(flag)}
+		if flag.Repeatable {
+			prop = &jsonSchema{Type: "array", Description: prop.Description, Items: &jsonSchema{Type: prop.Type}}
+		}
+		schema.Properties[flag.Name] = prop
+	}
+	return schema
+}
+
+func flagSchemaType(flag Flag) string {
+	switch flag.Kind() {
+	case FlagBool:
+		return "boolean"
+	case FlagInt:
+		return "integer"
+	case FlagFloat:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// toolResult is what a tool call printed, and how it exited.
+type toolResult struct {
+	Exit   int
+	Output string
+	Error  string
+}
+
+// structured returns the output as JSON when the command rendered it so.
+func (r toolResult) structured() (json.RawMessage, bool) {
+	output := bytes.TrimSpace([]byte(r.Output))
+	if len(output) == 0 || !json.Valid(output) {
+		return nil, false
+	}
+	return output, true
+}
+
+// call runs the tool with arguments in-process. Arguments that fail to match
+// the input schema are reported as usage errors without running anything.
+func (t tool) call(ctx context.Context, root *Command, program string, config *runConfig, arguments map[string]json.RawMessage) toolResult {
+	argv, stdin, err := t.argv(root, arguments)
+	if err != nil {
+		return toolResult{Exit: ExitUsage, Error: err.Error()}
+	}
+
+	var stdout bytes.Buffer
+	opts := Options{Context: ctx, Program: program, Stdout: &stdout, Stderr: io.Discard, config: config}
+	if stdin != nil {
+		opts.Stdin = strings.NewReader(*stdin)
+	}
+	code, err := execute(root, argv, opts)
+	result := toolResult{Exit: code, Output: stdout.String()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// argv builds the command line for arguments, with positionals after a --
+// so values that look like flags stay values. The text of a stdin argument
+// is returned separately to be piped in.
+func (t tool) argv(root *Command, arguments map[string]json.RawMessage) ([]string, *string, error) {
+	argv := append([]string{}, t.path...)
+	if slices.ContainsFunc(root.PersistentFlags, func(f Flag) bool { return f.Name == "output" }) {
+		argv = append(argv, "--output", "json")
+	}
+
+	properties := t.inputSchema().Properties
+	for name := range arguments {
+		if _, ok := properties[name]; !ok {
+			return nil, nil, fmt.Errorf("unknown argument: %s%s", name, DidYouMean(name, t.argumentNames()))
+		}
+	}
+
+	for _, flag := range t.flags {
+		raw, ok := arguments[flag.Name]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		values := []json.RawMessage{raw}
+		if flag.Repeatable {
+			values = nil
+			if err := json.Unmarshal(raw, &values); err != nil {
+				return nil, nil, fmt.Errorf("%s must be an array", flag.Name)
+			}
+		}
+		for _, raw := range values {
+			value, err := flagArgument(flag, raw)
+			if err != nil {
+				return nil, nil, err
+			}
+			if value != "" {
+				argv = append(argv, "--"+flag.Name+"="+value)
+			}
+		}
+	}
+
+	argv = append(argv, "--")
+	var stdin *string
+	for _, arg := range t.command.Args {
+		raw, ok := arguments[arg.Name]
+		if !ok || string(raw) == "null" {
+			if arg.required() {
+				return nil, nil, fmt.Errorf("missing required argument: %s", arg.Name)
+			}
+			continue
+		}
+		switch {
+		case arg.Stdin:
+			var text string
+			if err := json.Unmarshal(raw, &text); err != nil {
+				return nil, nil, fmt.Errorf("%s must be a string", arg.Name)
+			}
+			stdin = &text
+		case arg.Variadic:
+			var values []string
+			if err := json.Unmarshal(raw, &values); err != nil {
+				return nil, nil, fmt.Errorf("%s must be an array of strings", arg.Name)
+			}
+			argv = append(argv, values...)
+		default:
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, nil, fmt.Errorf("%s must be a string", arg.Name)
+			}
+			argv = append(argv, value)
+		}
+	}
+	return argv, stdin, nil
+}
+
+// flagArgument converts a JSON value to the flag's command-line value.
+func flagArgument(flag Flag, raw json.RawMessage) (string, error) {
+	switch flag.Kind() {
+	case FlagBool:
+		var v bool
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return "", fmt.Errorf("%s must be a boolean", flag.Name)
+		}
+		return strconv.FormatBool(v), nil
+	case FlagInt:
+		var v int
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return "", fmt.Errorf("%s must be an integer", flag.Name)
+		}
+		return strconv.Itoa(v), nil
+	case FlagFloat:
+		var v float64
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return "", fmt.Errorf("%s must be a number", flag.Name)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	default:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return "", fmt.Errorf("%s must be a string", flag.Name)
+		}
+		return v, flag.check(v)
+	}
+}
+
+func (t tool) argumentNames() []string {
+	var names []string
+	for _, arg := range t.command.Args {
+		names = append(names, arg.Name)
+	}
+	for _, flag := range t.flags {
+		names = append(names, flag.Name)
+	}
+	return names
+}
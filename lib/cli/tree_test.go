@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+// newUpperCommand returns a command that uppercases its text, line by line
+// when given --each-line.
+func newUpperCommand(name string) *cli.Command {
+	return cli.New(name, "Uppercase stdin").
+		WithArgs(cli.VariadicArg("text").FromStdin()).
+		WithRun(runUpper)
+}
+
+func runUpper(ctx *cli.Context, args []string) error {
+	if ctx.Records() {
+		return ctx.EachRecord("text", func(record string) (string, error) {
+			return strings.ToUpper(record), nil
+		})
+	}
+	text, err := ctx.TrimmedInput("text")
+	if err != nil {
+		return err
+	}
+	return ctx.Render(strings.ToUpper(text))
+}
+
+// newFailCommand returns a command that always fails with "boom".
+func newFailCommand() *cli.Command {
+	return cli.New("fail", "Fail").WithRun(func(ctx *cli.Context, args []string) error {
+		return errors.New("boom")
+	})
+}
+
+// checkInterrupted runs args against a stdin that never ends and fails the
+// test unless cancelling the run stops it with the interrupted exit code.
+func checkInterrupted(t *testing.T, root *cli.Command, args ...string) {
+	t.Helper()
+	in, input := io.Pipe()
+	defer input.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	var stderr bytes.Buffer
+	done := make(chan int, 1)
+	go func() {
+		done <- cli.ExecuteWith(root, args, cli.Options{Context: ctx, Stdin: in, Stdout: io.Discard, Stderr: &stderr})
+	}()
+
+	cancel()
+	select {
+	case code := <-done:
+		if code != cli.ExitInterrupted {
+			t.Fatalf("%s = %d, want %d; stderr = %s", args, code, cli.ExitInterrupted, stderr.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s still reading stdin after cancellation", args)
+	}
+}
@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var helpFlag = BoolFlag("help", "h", "Show help")
+
+func Validate(root *Command) error {
+	var errs []string
+	validateCommand(root, []string{root.Name}, []Flag{helpFlag}, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, "\n"))
+}
+
+func validateCommand(c *Command, path []string, inherited []Flag, errs *[]string) {
+	cmdPath := strings.Join(path, " ")
+	if c.Name == "" {
+		*errs = append(*errs, fmt.Sprintf("command at %q has empty name", cmdPath))
+	}
+
+	if c.DefaultSubcommand != "" && c.findSubcommand(c.DefaultSubcommand) == nil {
+		*errs = append(*errs, fmt.Sprintf("command %q has unknown default subcommand %q", cmdPath, c.DefaultSubcommand))
+	}
+
+	for _, alias := range c.Aliases {
+		if strings.EqualFold(alias, c.Name) {
+			*errs = append(*errs, fmt.Sprintf("command %q has alias %q matching its own name", cmdPath, alias))
+		}
+	}
+
+	seen := make(map[string]string)
+	addToken := func(token, kind string) {
+		if token == "" {
+			*errs = append(*errs, fmt.Sprintf("command %q has empty %s", cmdPath, kind))
+			return
+		}
+		if isHelpArg(token) {
+			*errs = append(*errs, fmt.Sprintf("command %q has %s %q shadowed by built-in help", cmdPath, kind, token))
+			return
+		}
+		key := strings.ToLower(token)
+		if prev, ok := seen[key]; ok {
+			*errs = append(*errs, fmt.Sprintf("command %q has duplicate token %q (%s conflicts with %s)", cmdPath, token, kind, prev))
+			return
+		}
+		seen[key] = kind
+	}
+
+	for _, sub := range c.Subcommands {
+		addToken(sub.Name, fmt.Sprintf("subcommand %q", sub.Name))
+		for _, alias := range sub.Aliases {
+			addToken(alias, fmt.Sprintf("alias of %q", sub.Name))
+		}
+	}
+
+	validateMacros(c, c.macros(nil), cmdPath, errs)
+	validatePlaceholders(c, cmdPath, errs)
+	validateArgs(c, cmdPath, errs)
+	validateFlags(c, cmdPath, inherited, errs)
+
+	for _, ex := range c.Examples {
+		validateExample(c, path, inherited, ex, errs)
+	}
+	for _, ex := range c.ExampleSpecs {
+		validateExampleSpec(c, path, inherited, ex, errs)
+	}
+
+	childInherited := append(append([]Flag{}, inherited...), c.PersistentFlags...)
+	for _, sub := range c.Subcommands {
+		validateCommand(sub, append(path, sub.Name), childInherited, errs)
+	}
+}
+
+// validatePlaceholders reports %cmd% and %prog% in fields that Help does not
+// expand.
+func validatePlaceholders(c *Command, cmdPath string, errs *[]string) {
+	check := func(value, where string) {
+		for _, placeholder := range []string{"%cmd%", "%prog%"} {
+			if strings.Contains(value, placeholder) {
+				*errs = append(*errs, fmt.Sprintf("command %q uses %s in %s, where it is not expanded", cmdPath, placeholder, where))
+			}
+		}
+	}
+
+	check(c.Name, "its name")
+	check(c.Description, "its description")
+	for _, alias := range c.Aliases {
+		check(alias, fmt.Sprintf("alias %q", alias))
+	}
+	for _, arg := range c.Args {
+		check(arg.Name, "an arg name")
+	}
+	for _, flag := range c.ownFlags() {
+		check(flag.Name+flag.Short+flag.Value+flag.Description+flag.Default, "flag --"+flag.Name)
+	}
+	for _, section := range c.Sections {
+		check(section.Title, fmt.Sprintf("section title %q", section.Title))
+	}
+	for _, example := range c.ExampleSpecs {
+		check(example.Note, "a structured example note")
+		for _, arg := range example.Args {
+			check(arg, "structured example args")
+		}
+	}
+}
+
+// validateArgs reports declarations that bindArgs cannot split unambiguously:
+// nothing may follow a variadic arg, and args that may come from stdin are
+// trailing inputs, so they must be last.
+func validateArgs(c *Command, cmdPath string, errs *[]string) {
+	names := make(map[string]bool)
+	variadic := ""
+	stdin := ""
+
+	for _, arg := range c.Args {
+		if arg.Name == "" {
+			*errs = append(*errs, fmt.Sprintf("command %q has an arg with empty name", cmdPath))
+			continue
+		}
+		if names[arg.Name] {
+			*errs = append(*errs, fmt.Sprintf("command %q has duplicate arg %q", cmdPath, arg.Name))
+		}
+		names[arg.Name] = true
+
+		if stdin != "" {
+			*errs = append(*errs, fmt.Sprintf("command %q declares arg %q after stdin arg %q; stdin args must be last", cmdPath, arg.Name, stdin))
+		} else if variadic != "" {
+			*errs = append(*errs, fmt.Sprintf("command %q declares arg %q after variadic arg %q", cmdPath, arg.Name, variadic))
+		}
+
+		if arg.Variadic {
+			variadic = arg.Name
+		}
+		if arg.Stdin {
+			stdin = arg.Name
+		}
+	}
+
+	if len(c.Args) > 0 && len(c.Subcommands) > 0 && c.Run == nil {
+		*errs = append(*errs, fmt.Sprintf("command %q declares args but has no run function", cmdPath))
+	}
+	if len(c.Args) > 0 && len(c.Subcommands) > 0 && c.prefixMatching() {
+		*errs = append(*errs, fmt.Sprintf("command %q declares args alongside prefix-matched subcommands; an argument could select a subcommand", cmdPath))
+	}
+}
+
+func validateFlags(c *Command, cmdPath string, inherited []Flag, errs *[]string) {
+	long := make(map[string]bool)
+	short := make(map[string]bool)
+
+	for _, flag := range c.ownFlags() {
+		if flag.Name == "" {
+			*errs = append(*errs, fmt.Sprintf("command %q has a flag with empty name", cmdPath))
+			continue
+		}
+		if strings.HasPrefix(flag.Name, "-") || strings.ContainsAny(flag.Name, "= ") {
+			*errs = append(*errs, fmt.Sprintf("command %q has invalid flag name %q", cmdPath, flag.Name))
+		}
+		if flag.Short != "" && len(flag.Short) != 1 {
+			*errs = append(*errs, fmt.Sprintf("command %q flag --%s has short %q; shorts must be one character", cmdPath, flag.Name, flag.Short))
+		}
+		if flag.Default != "" {
+			if err := flag.check(flag.Default); err != nil {
+				*errs = append(*errs, fmt.Sprintf("command %q flag --%s has bad default: %v", cmdPath, flag.Name, err))
+			}
+		}
+
+		if long[flag.Name] {
+			*errs = append(*errs, fmt.Sprintf("command %q has duplicate flag --%s", cmdPath, flag.Name))
+		}
+		long[flag.Name] = true
+		if flag.Short != "" {
+			if short[flag.Short] {
+				*errs = append(*errs, fmt.Sprintf("command %q has duplicate short flag -%s", cmdPath, flag.Short))
+			}
+			short[flag.Short] = true
+		}
+
+		for _, parent := range inherited {
+			if parent.Name == flag.Name {
+				*errs = append(*errs, fmt.Sprintf("command %q flag --%s shadows inherited flag --%s", cmdPath, flag.Name, parent.Name))
+			}
+			if flag.Short != "" && parent.Short == flag.Short {
+				*errs = append(*errs, fmt.Sprintf("command %q flag -%s shadows inherited flag --%s", cmdPath, flag.Short, parent.Name))
+			}
+		}
+	}
+}
+
+type exampleTarget struct {
+	command   *Command
+	path      []string
+	inherited []Flag
+	remaining []string
+	help      bool
+	err       error
+}
+
+// resolveExample walks example tokens from c the way Execute does and returns
+// the command they reach along with the leftover arguments.
+func resolveExample(c *Command, path []string, inherited []Flag, tokens []string) exampleTarget {
+	current := c
+	currentPath := append([]string{}, path...)
+	inherited = append([]Flag{}, inherited...)
+	remaining := tokens
+	var flagErr error
+
+	for len(remaining) > 0 {
+		if isHelpArg(remaining[0]) {
+			return exampleTarget{command: current, path: currentPath, help: true}
+		}
+		if isFlagToken(remaining[0]) && len(current.Subcommands) > 0 {
+			n, err := levelFlags(current, inherited, make(map[string][]string)).parseOne(remaining)
+			if err != nil && !errors.Is(err, errHelp) {
+				if flagErr == nil {
+					flagErr = err
+				}
+				n = 1
+			}
+			remaining = remaining[n:]
+			continue
+		}
+		next, _ := current.matchSubcommand(remaining[0])
+		if next == nil {
+			break
+		}
+		inherited = append(inherited, current.PersistentFlags...)
+		current = next
+		currentPath = append(currentPath, next.Name)
+		remaining = remaining[1:]
+	}
+
+	if len(remaining) == 0 && current.Run == nil && current.DefaultSubcommand != "" {
+		if next := current.findSubcommand(current.DefaultSubcommand); next != nil {
+			inherited = append(inherited, current.PersistentFlags...)
+			current = next
+			currentPath = append(currentPath, next.Name)
+		}
+	}
+
+	return exampleTarget{
+		command:   current,
+		path:      currentPath,
+		inherited: inherited,
+		remaining: remaining,
+		err:       flagErr,
+	}
+}
+
+func validateExampleFlags(target exampleTarget) error {
+	if target.err != nil {
+		return target.err
+	}
+	flags := newFlagSet(append(append([]Flag{}, target.inherited...), target.command.ownFlags()...))
+	_, err := flags.parse(target.remaining)
+	if errors.Is(err, errHelp) {
+		return nil
+	}
+	return err
+}
+
+func validateExample(c *Command, path []string, inherited []Flag, example string, errs *[]string) {
+	cmdPath := strings.Join(path, " ")
+	line := strings.TrimSpace(expandPlaceholders(example, cmdPath))
+	if line == "" {
+		return
+	}
+	if i := strings.Index(line, " #"); i >= 0 {
+		line = strings.TrimSpace(line[:i])
+	}
+	if line == "" {
+		return
+	}
+
+	tokens := strings.Fields(line)
+	start := findPathStart(tokens, path)
+	if start < 0 {
+		*errs = append(*errs, fmt.Sprintf("example %q for %q does not include full command path", example, cmdPath))
+		return
+	}
+
+	tokens = tokens[start+len(path):]
+	for i, token := range tokens {
+		if token == "|" {
+			tokens = tokens[:i]
+			break
+		}
+	}
+
+	target := resolveExample(c, path, inherited, tokens)
+	if target.help {
+		return
+	}
+
+	if len(target.remaining) > 0 && len(target.command.Subcommands) > 0 && target.command.findSubcommand(target.remaining[0]) == nil {
+		if _, err := target.command.matchSubcommand(target.remaining[0]); err != nil {
+			*errs = append(*errs, fmt.Sprintf("example %q for %q: %v", example, cmdPath, err))
+		} else {
+			*errs = append(*errs, fmt.Sprintf("example %q for %q has unknown %s %q", example, cmdPath, unknownLabel(target.path), target.remaining[0]))
+		}
+		return
+	}
+
+	if err := validateExampleFlags(target); err != nil {
+		*errs = append(*errs, fmt.Sprintf("example %q for %q: %v", example, cmdPath, err))
+	}
+}
+
+func findPathStart(tokens []string, path []string) int {
+	if len(tokens) < len(path) {
+		return -1
+	}
+	for i := 0; i <= len(tokens)-len(path); i++ {
+		ok := true
+		for j := 0; j < len(path); j++ {
+			if tokens[i+j] != path[j] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateExampleSpec(c *Command, path []string, inherited []Flag, example Example, errs *[]string) {
+	cmdPath := strings.Join(path, " ")
+
+	args, err := example.Argv()
+	if err != nil {
+		*errs = append(*errs, fmt.Sprintf("structured example %q for %q: %v", strings.Join(example.Args, " "), cmdPath, err))
+		return
+	}
+	target := resolveExample(c, path, inherited, args)
+	if target.help {
+		return
+	}
+
+	if len(target.remaining) > 0 && len(target.command.Subcommands) > 0 && target.command.findSubcommand(target.remaining[0]) == nil {
+		if _, err := target.command.matchSubcommand(target.remaining[0]); err != nil {
+			*errs = append(*errs, fmt.Sprintf("structured example %q for %q: %v", strings.Join(example.Args, " "), cmdPath, err))
+		} else {
+			*errs = append(*errs, fmt.Sprintf("structured example for %q has unknown %s %q", cmdPath, unknownLabel(target.path), target.remaining[0]))
+		}
+		return
+	}
+
+	if err := validateExampleFlags(target); err != nil {
+		*errs = append(*errs, fmt.Sprintf("structured example %q for %q: %v", strings.Join(example.Args, " "), cmdPath, err))
+	}
+}
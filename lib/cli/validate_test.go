@@ -0,0 +1,152 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func noop(ctx *cli.Context, args []string) error {
+	return nil
+}
+
+func TestValidateReportsMistakes(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  *cli.Command
+		want string
+	}{
+		{
+			name: "sibling alias collision",
+			cmd: cli.New("root", "Root").Register(
+				cli.New("slug", "Slug").WithAliases("s").WithRun(noop),
+				cli.New("sound", "Sound").WithAliases("s").WithRun(noop),
+			),
+			want: `duplicate token "s"`,
+		},
+		{
+			name: "alias shadows sibling name",
+			cmd: cli.New("root", "Root").Register(
+				cli.New("copy", "Copy").WithRun(noop),
+				cli.New("clip", "Clip").WithAliases("copy").WithRun(noop),
+			),
+			want: `duplicate token "copy"`,
+		},
+		{
+			name: "alias matches own name",
+			cmd:  cli.New("root", "Root").WithAliases("ROOT").WithRun(noop),
+			want: `alias "ROOT" matching its own name`,
+		},
+		{
+			name: "subcommand shadowed by help",
+			cmd: cli.New("root", "Root").Register(
+				cli.New("helper", "Helper").WithAliases("help").WithRun(noop),
+			),
+			want: "shadowed by built-in help",
+		},
+		{
+			name: "required after variadic",
+			cmd: cli.New("case", "Case").
+				WithArgs(cli.VariadicArg("conversion"), cli.RequiredArg("text")).
+				WithRun(noop),
+			want: `declares arg "text" after variadic arg "conversion"`,
+		},
+		{
+			name: "stdin arg after variadic",
+			cmd: cli.New("case", "Case").
+				WithArgs(cli.VariadicArg("conversion"), cli.RequiredArg("text").FromStdin()).
+				WithRun(noop),
+			want: `declares arg "text" after variadic arg "conversion"`,
+		},
+		{
+			name: "arg after stdin arg",
+			cmd: cli.New("tool", "Tool").
+				WithArgs(cli.RequiredArg("text").FromStdin(), cli.OptionalArg("mode")).
+				WithRun(noop),
+			want: `stdin args must be last`,
+		},
+		{
+			name: "duplicate long flag",
+			cmd: cli.New("tool", "Tool").
+				RegisterFlags(cli.BoolFlag("all", "a", "All"), cli.BoolFlag("all", "", "All again")).
+				WithRun(noop),
+			want: "duplicate flag --all",
+		},
+		{
+			name: "duplicate short flag",
+			cmd: cli.New("tool", "Tool").
+				RegisterFlags(cli.BoolFlag("all", "a", "All"), cli.BoolFlag("any", "a", "Any")).
+				WithRun(noop),
+			want: "duplicate short flag -a",
+		},
+		{
+			name: "flag shadows help",
+			cmd: cli.New("tool", "Tool").
+				RegisterFlags(cli.BoolFlag("human", "h", "Human readable")).
+				WithRun(noop),
+			want: "flag -h shadows inherited flag --help",
+		},
+		{
+			name: "bad default",
+			cmd: cli.New("tool", "Tool").
+				RegisterFlags(cli.IntFlag("count", "c", "", "Count").WithDefault("lots")).
+				WithRun(noop),
+			want: "flag --count has bad default",
+		},
+		{
+			name: "placeholder in description",
+			cmd:  cli.New("tool", "Run %cmd% things").WithRun(noop),
+			want: "uses %cmd% in its description",
+		},
+		{
+			name: "placeholder in example spec",
+			cmd:  cli.New("tool", "Tool").WithExample("Run %cmd%", "x").WithArgs(cli.RequiredArg("x")).WithRun(noop),
+			want: "uses %cmd% in a structured example note",
+		},
+		{
+			name: "program placeholder in description",
+			cmd:  cli.New("tool", "Run %prog% things").WithRun(noop),
+			want: "uses %prog% in its description",
+		},
+		{
+			name: "undeclared flag in example",
+			cmd: cli.New("tool", "Tool").
+				WithArgs(cli.RequiredArg("text")).
+				WithExamples(`%cmd% --fast "x"  # fast`).
+				WithRun(noop),
+			want: "unknown flag: --fast",
+		},
+		{
+			name: "undeclared flag in nested example spec",
+			cmd: cli.New("root", "Root").
+				WithExample("List all", "list", "-a").
+				Register(cli.New("list", "List").WithRun(noop)),
+			want: "unknown flag: -a",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := cli.Validate(tc.cmd)
+			if err == nil {
+				t.Fatalf("Validate() = nil, want error containing %q", tc.want)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsTrailingVariadicStdinArg(t *testing.T) {
+	cmd := cli.New("case", "Case").
+		WithArgs(cli.RequiredArg("conversion"), cli.VariadicArg("text").FromStdin()).
+		RegisterFlags(cli.StringFlag("style", "s", "style", "Style")).
+		WithExamples(`%cmd% kebab --style=ap "Hello World"  # hello-world`).
+		WithRun(noop)
+
+	if err := cli.Validate(cmd); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// BuildInfo describes the running binary. Version, Commit and BuildDate come
+// from -ldflags; the rest is read from the Go build info embedded in the binary.
+type BuildInfo struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit,omitempty"`
+	BuildDate string   `json:"build_date,omitempty"`
+	Modified  bool     `json:"modified,omitempty"`
+	GoVersion string   `json:"go_version"`
+	Platform  string   `json:"platform"`
+	Module    *Module  `json:"module,omitempty"`
+	Deps      []Module `json:"deps,omitempty"`
+}
+
+// Module is a module compiled into the binary.
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// NewBuildInfo combines the values injected with -ldflags with the embedded
+// build info. Empty commit and date fall back to the VCS stamp go build
+// records when building from a checkout.
+func NewBuildInfo(version, commit, buildDate string) BuildInfo {
+	if version == "" {
+		version = "dev"
+	}
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if bi.Main.Path != "" {
+		info.Module = &Module{Path: bi.Main.Path, Version: bi.Main.Version}
+	}
+	for _, dep := range bi.Deps {
+		info.Deps = append(info.Deps, Module{Path: dep.Path, Version: dep.Version})
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+func (b BuildInfo) Text() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "version:  %s\n", b.Version)
+	if b.Commit != "" {
+		commit := b.Commit
+		if b.Modified {
+			commit += " (modified)"
+		}
+		fmt.Fprintf(&s, "commit:   %s\n", commit)
+	}
+	if b.BuildDate != "" {
+		fmt.Fprintf(&s, "built:    %s\n", b.BuildDate)
+	}
+	fmt.Fprintf(&s, "go:       %s\n", b.GoVersion)
+	fmt.Fprintf(&s, "platform: %s", b.Platform)
+	if b.Module != nil {
+		fmt.Fprintf(&s, "\nmodule:   %s %s", b.Module.Path, b.Module.Version)
+	}
+	for _, dep := range b.Deps {
+		fmt.Fprintf(&s, "\ndep:      %s %s", dep.Path, dep.Version)
+	}
+	return s.String()
+}
+
+// WithVersion adds a --version flag to a root command that prints info and
+// exits instead of running anything.
+func (c *Command) WithVersion(info BuildInfo) *Command {
+	c.Version = &info
+	c.Flags = append(c.Flags, BoolFlag("version", "", "Show version information").WithoutConfig())
+	return c
+}
+
+// NewVersionCommand returns a command that prints info; pair it with
+// OutputFlag for a JSON form.
+func NewVersionCommand(name string, info BuildInfo) *Command {
+	return New(name, "Show version and build information").
+		WithExample("Show version information").
+		WithRun(func(ctx *Context, args []string) error {
+			return ctx.Render(info)
+		}).
+		AsUtility()
+}
@@ -0,0 +1,111 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/khinshankhan/yui/lib/cli"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	info := cli.NewBuildInfo("", "abc123", "2024.01.02")
+	if info.Version != "dev" || info.Commit != "abc123" || info.BuildDate != "2024.01.02" {
+		t.Fatalf("NewBuildInfo() = %+v, want ldflags values kept and empty version as dev", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Fatalf("NewBuildInfo().GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	info := cli.BuildInfo{Version: "1.2.3", Commit: "abc123", GoVersion: "go1.24", Platform: "linux/amd64"}
+
+	tests := []struct {
+		name string
+		cmd  func(ran *bool) *cli.Command
+		args []string
+	}{
+		{
+			name: "leaf root after args",
+			cmd: func(ran *bool) *cli.Command {
+				return cli.New("case", "Case").
+					WithArgs(cli.RequiredArg("text")).
+					WithRun(func(ctx *cli.Context, args []string) error { *ran = true; return nil }).
+					WithVersion(info)
+			},
+			args: []string{"x", "--version"},
+		},
+		{
+			name: "group root without a subcommand",
+			cmd: func(ran *bool) *cli.Command {
+				return cli.New("net", "Net").
+					Register(cli.New("ip", "IP").WithRun(func(ctx *cli.Context, args []string) error { *ran = true; return nil })).
+					WithVersion(info)
+			},
+			args: []string{"--version"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ran := false
+			var stdout, stderr bytes.Buffer
+			if code := cli.Execute(tc.cmd(&ran), tc.args, &stdout, &stderr); code != 0 {
+				t.Fatalf("Execute(%q) code = %d, stderr = %s", tc.args, code, stderr.String())
+			}
+			if ran {
+				t.Fatalf("Execute(%q) ran the command, want version only", tc.args)
+			}
+			if !strings.Contains(stdout.String(), "version:  1.2.3") || !strings.Contains(stdout.String(), "commit:   abc123") {
+				t.Fatalf("Execute(%q) stdout = %q, want version text", tc.args, stdout.String())
+			}
+		})
+	}
+}
+
+func TestVersionCommandJSON(t *testing.T) {
+	info := cli.BuildInfo{Version: "1.2.3", GoVersion: "go1.24", Platform: "linux/amd64"}
+	root := cli.New("yui", "Yui").
+		RegisterPersistentFlags(cli.OutputFlag()).
+		Register(cli.NewVersionCommand("version", info))
+
+	var stdout, stderr bytes.Buffer
+	if code := cli.Execute(root, []string{"version", "-o", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Execute() code = %d, stderr = %s", code, stderr.String())
+	}
+
+	var got cli.BuildInfo
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("version -o json is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.Version != "1.2.3" || got.Platform != "linux/amd64" {
+		t.Fatalf("version -o json = %+v, want %+v", got, info)
+	}
+}
+
+func TestVersionFlagJSON(t *testing.T) {
+	info := cli.BuildInfo{Version: "1.2.3", GoVersion: "go1.24", Platform: "linux/amd64"}
+
+	for _, args := range [][]string{{"--version", "-o", "json"}, {"--output", "json", "x", "--version"}} {
+		root := cli.New("case", "Case").
+			WithArgs(cli.RequiredArg("text")).
+			RegisterPersistentFlags(cli.OutputFlag()).
+			WithRun(func(ctx *cli.Context, args []string) error { return nil }).
+			WithVersion(info)
+
+		var stdout, stderr bytes.Buffer
+		if code := cli.Execute(root, args, &stdout, &stderr); code != 0 {
+			t.Fatalf("Execute(%q) code = %d, stderr = %s", args, code, stderr.String())
+		}
+		var got cli.BuildInfo
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("Execute(%q) is not JSON: %v\n%s", args, err, stdout.String())
+		}
+		if got.Version != "1.2.3" {
+			t.Fatalf("Execute(%q) = %+v, want %+v", args, got, info)
+		}
+	}
+}
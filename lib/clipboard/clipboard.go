@@ -1,33 +1,91 @@
 package clipboard
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/khinshankhan/yui/lib/sysexec"
 )
 
+// ErrNoBackend is returned when no clipboard tool is installed.
+var ErrNoBackend = errors.New("no clipboard backend found; install pbcopy/pbpaste, wl-clipboard, xclip, xsel, or PowerShell")
+
 func Copy(text string) error {
-	backend, err := detectBackend()
+	return CopyContext(context.Background(), text)
+}
+
+// CopyContext is Copy, stopping the clipboard tool when ctx is done.
+func CopyContext(ctx context.Context, text string) error {
+	return CopyPreferring(ctx, "", text)
+}
+
+// CopyPreferring is CopyContext using the named backend when it is
+// installed, and the usual detection order otherwise.
+func CopyPreferring(ctx context.Context, backend, text string) error {
+	if c, ok := fromContext(ctx); ok {
+		return c.Copy(ctx, text)
+	}
+	b, err := detectBackend(backend)
 	if err != nil {
 		return err
 	}
-	return sysexec.RunInput(backend, "copy", text)
+	return sysexec.RunInputContext(ctx, b, "copy", text)
 }
 
 func Paste() (string, error) {
-	backend, err := detectBackend()
+	return PasteContext(context.Background())
+}
+
+// PasteContext is Paste, stopping the clipboard tool when ctx is done.
+func PasteContext(ctx context.Context) (string, error) {
+	return PastePreferring(ctx, "")
+}
+
+// PastePreferring is PasteContext using the named backend when it is
+// installed, and the usual detection order otherwise.
+func PastePreferring(ctx context.Context, backend string) (string, error) {
+	if c, ok := fromContext(ctx); ok {
+		return c.Paste(ctx)
+	}
+	b, err := detectBackend(backend)
 	if err != nil {
 		return "", err
 	}
-	return sysexec.RunOutput(backend, "paste", 2*time.Second)
+	return sysexec.RunOutputContext(ctx, b, "paste", 2*time.Second)
+}
+
+// Backends returns the names of the backends tried on this platform.
+func Backends() []string {
+	var names []string
+	for _, b := range candidates() {
+		if !slices.Contains(names, b.Name) {
+			names = append(names, b.Name)
+		}
+	}
+	return names
 }
 
-func detectBackend() (sysexec.Backend, error) {
-	b, err := sysexec.Detect(candidates())
+// detectBackend returns the first installed backend, trying prefer first.
+func detectBackend(prefer string) (sysexec.Backend, error) {
+	all := candidates()
+	var ordered []sysexec.Backend
+	for _, b := range all {
+		if b.Name == prefer {
+			ordered = append(ordered, b)
+		}
+	}
+	for _, b := range all {
+		if b.Name != prefer {
+			ordered = append(ordered, b)
+		}
+	}
+
+	b, err := sysexec.Detect(ordered)
 	if err != nil {
-		return sysexec.Backend{}, fmt.Errorf("no clipboard backend found; install pbcopy/pbpaste, wl-clipboard, xclip, xsel, or PowerShell")
+		return sysexec.Backend{}, ErrNoBackend
 	}
 	return b, nil
 }
@@ -1,8 +1,12 @@
 package clipboard
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/khinshankhan/yui/lib/sysexec"
 )
@@ -28,7 +32,7 @@ func TestDetectBackendPrefersWaylandWhenAvailable(t *testing.T) {
 		}
 	}
 
-	backend, err := detectBackend()
+	backend, err := detectBackend("")
 	if err != nil {
 		t.Fatalf("detectBackend() error = %v", err)
 	}
@@ -59,7 +63,7 @@ func TestDetectBackendFallsBackToXclip(t *testing.T) {
 		}
 	}
 
-	backend, err := detectBackend()
+	backend, err := detectBackend("")
 	if err != nil {
 		t.Fatalf("detectBackend() error = %v", err)
 	}
@@ -85,7 +89,7 @@ func TestDetectBackendOnWindowsUsesPowerShell(t *testing.T) {
 		return "", os.ErrNotExist
 	}
 
-	backend, err := detectBackend()
+	backend, err := detectBackend("")
 	if err != nil {
 		t.Fatalf("detectBackend() error = %v", err)
 	}
@@ -94,3 +98,81 @@ func TestDetectBackendOnWindowsUsesPowerShell(t *testing.T) {
 		t.Fatalf("detectBackend() backend = %q, want powershell", backend.Name)
 	}
 }
+
+func TestPasteContextStopsOnCancel(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", ":0")
+
+	origGOOS := sysexec.GOOS
+	origLookPath := sysexec.LookPath
+	origNewCmdContext := sysexec.NewCmdContext
+	t.Cleanup(func() {
+		sysexec.GOOS = origGOOS
+		sysexec.LookPath = origLookPath
+		sysexec.NewCmdContext = origNewCmdContext
+	})
+
+	sysexec.GOOS = "linux"
+	sysexec.LookPath = func(file string) (string, error) {
+		return "/usr/bin/" + file, nil
+	}
+	sysexec.NewCmdContext = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sleep", "5")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := PasteContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("PasteContext() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("PasteContext() took %v after cancel, want the backend killed", elapsed)
+	}
+}
+
+func TestDetectBackendPrefersNamedBackend(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", ":0")
+
+	origGOOS := sysexec.GOOS
+	origLookPath := sysexec.LookPath
+	t.Cleanup(func() {
+		sysexec.GOOS = origGOOS
+		sysexec.LookPath = origLookPath
+	})
+
+	sysexec.GOOS = "linux"
+	sysexec.LookPath = func(file string) (string, error) {
+		switch file {
+		case "xclip", "xsel":
+			return "/usr/bin/" + file, nil
+		default:
+			return "", os.ErrNotExist
+		}
+	}
+
+	tests := []struct {
+		prefer string
+		want   string
+	}{
+		{prefer: "xsel", want: "xsel"},
+		{prefer: "wl-clipboard", want: "xclip"},
+		{prefer: "", want: "xclip"},
+	}
+
+	for _, tc := range tests {
+		backend, err := detectBackend(tc.prefer)
+		if err != nil {
+			t.Fatalf("detectBackend(%q) error = %v", tc.prefer, err)
+		}
+		if backend.Name != tc.want {
+			t.Fatalf("detectBackend(%q) backend = %q, want %s", tc.prefer, backend.Name, tc.want)
+		}
+	}
+}
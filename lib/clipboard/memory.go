@@ -0,0 +1,45 @@
+package clipboard
+
+import (
+	"context"
+	"sync"
+)
+
+// Clipboard is a clipboard that Copy and Paste can use in place of the
+// system's, such as Memory in tests.
+type Clipboard interface {
+	Copy(ctx context.Context, text string) error
+	Paste(ctx context.Context) (string, error)
+}
+
+type clipboardKey struct{}
+
+// WithClipboard returns a context under which the Context and Preferring
+// functions use c instead of detecting a system backend.
+func WithClipboard(ctx context.Context, c Clipboard) context.Context {
+	return context.WithValue(ctx, clipboardKey{}, c)
+}
+
+func fromContext(ctx context.Context) (Clipboard, bool) {
+	c, ok := ctx.Value(clipboardKey{}).(Clipboard)
+	return c, ok
+}
+
+// Memory is an in-process clipboard. The zero value is empty.
+type Memory struct {
+	mu   sync.Mutex
+	text string
+}
+
+func (m *Memory) Copy(ctx context.Context, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.text = text
+	return nil
+}
+
+func (m *Memory) Paste(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.text, nil
+}
@@ -1,10 +1,15 @@
 package nettools
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 )
 
+// ErrNoAddress is returned when no usable local IP address is found.
+var ErrNoAddress = errors.New("no local IP address found")
+
 // NetworkInterface represents information about a network interface.
 type NetworkInterface struct {
 	Name       string   `json:"name"`
@@ -101,14 +106,23 @@ func getPrimaryFallback() (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("no local IP address found")
+	return "", ErrNoAddress
 }
 
 // GetPrimaryLocalIP returns the primary local IP address (typically the one used for outbound connections).
 func GetPrimaryLocalIP() (string, error) {
+	return GetPrimaryLocalIPContext(context.Background())
+}
+
+// GetPrimaryLocalIPContext is GetPrimaryLocalIP, giving up when ctx is done.
+func GetPrimaryLocalIPContext(ctx context.Context) (string, error) {
 	// This trick finds the preferred outbound IP by connecting to an external address
 	// (doesn't actually establish a connection, just determines the route)
-	conn, err := net.Dial("udp", "8.8.8.8:80")
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", "8.8.8.8:80")
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if err != nil {
 		// Fallback: try to get any non-loopback IP
 		return getPrimaryFallback()
@@ -1,33 +1,47 @@
 package sound
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"runtime"
 )
 
 var (
-	lookPath = exec.LookPath
-	command  = exec.Command
-	goos     = runtime.GOOS
+	lookPath       = exec.LookPath
+	commandContext = exec.CommandContext
+	goos           = runtime.GOOS
 )
 
+// ErrNoPlayer is returned when no supported sound player is installed.
+var ErrNoPlayer = errors.New("no sound player found; install afplay, paplay, aplay, ffplay, sox, or mpv")
+
 type player struct {
 	bin  string
 	args func(file string) []string
 }
 
 func Play(file string) error {
+	return PlayContext(context.Background(), file)
+}
+
+// PlayContext is Play, killing the player when ctx is done.
+func PlayContext(ctx context.Context, file string) error {
 	for _, p := range players() {
 		if _, err := lookPath(p.bin); err == nil {
-			cmd := command(p.bin, p.args(file)...)
-			if output, err := cmd.CombinedOutput(); err != nil {
+			cmd := commandContext(ctx, p.bin, p.args(file)...)
+			output, err := cmd.CombinedOutput()
+			if ctx.Err() != nil {
+				return fmt.Errorf("%s: %w", p.bin, ctx.Err())
+			}
+			if err != nil {
 				return fmt.Errorf("%s failed: %w: %s", p.bin, err, output)
 			}
 			return nil
 		}
 	}
-	return fmt.Errorf("no sound player found; install afplay, paplay, aplay, ffplay, sox, or mpv")
+	return ErrNoPlayer
 }
 
 func players() []player {
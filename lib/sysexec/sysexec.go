@@ -3,6 +3,7 @@ package sysexec
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
@@ -11,11 +12,15 @@ import (
 )
 
 var (
-	LookPath = exec.LookPath
-	NewCmd   = exec.Command
-	GOOS     = runtime.GOOS
+	LookPath      = exec.LookPath
+	NewCmd        = exec.Command
+	NewCmdContext = exec.CommandContext
+	GOOS          = runtime.GOOS
 )
 
+// ErrNoBackend is returned by Detect when none of the candidates is installed.
+var ErrNoBackend = errors.New("no suitable backend found")
+
 type Cmd struct {
 	Args             []string
 	DetachAfterStart bool
@@ -32,7 +37,7 @@ func Detect(candidates []Backend) (Backend, error) {
 			return b, nil
 		}
 	}
-	return Backend{}, fmt.Errorf("no suitable backend found")
+	return Backend{}, ErrNoBackend
 }
 
 func allAvailable(b Backend) bool {
@@ -56,14 +61,21 @@ func Available(name string) bool {
 }
 
 func RunInput(b Backend, op, text string) error {
+	return RunInputContext(context.Background(), b, op, text)
+}
+
+// RunInputContext is RunInput with cancellation: the backend process is
+// killed when ctx is done and the context's error is returned. A detached
+// process is only killed if ctx ends before it is released.
+func RunInputContext(ctx context.Context, b Backend, op, text string) error {
 	cmd, ok := b.Cmds[op]
 	if !ok {
 		return fmt.Errorf("%s: unknown operation %q", b.Name, op)
 	}
 
-	c := NewCmd(cmd.Args[0], cmd.Args[1:]...)
-
 	if cmd.DetachAfterStart {
+		// Not tied to ctx, so the process outlives us once released.
+		c := NewCmd(cmd.Args[0], cmd.Args[1:]...)
 		applyDetachedProcessAttrs(c)
 
 		stdin, err := c.StdinPipe()
@@ -97,13 +109,20 @@ func RunInput(b Backend, op, text string) error {
 				return fmt.Errorf("%s %s failed: %w: %s", b.Name, op, err, bytes.TrimSpace(stderr.Bytes()))
 			}
 			return nil
+		case <-ctx.Done():
+			_ = c.Process.Kill()
+			return fmt.Errorf("%s %s: %w", b.Name, op, ctx.Err())
 		case <-time.After(150 * time.Millisecond):
 			return c.Process.Release()
 		}
 	}
 
+	c := NewCmdContext(ctx, cmd.Args[0], cmd.Args[1:]...)
 	c.Stdin = bytes.NewBufferString(text)
 	output, err := c.CombinedOutput()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s %s: %w", b.Name, op, ctx.Err())
+	}
 	if err != nil {
 		return fmt.Errorf("%s %s failed: %w: %s", b.Name, op, err, bytes.TrimSpace(output))
 	}
@@ -111,17 +130,26 @@ func RunInput(b Backend, op, text string) error {
 }
 
 func RunOutput(b Backend, op string, timeout time.Duration) (string, error) {
+	return RunOutputContext(context.Background(), b, op, timeout)
+}
+
+// RunOutputContext is RunOutput with cancellation: the backend process is
+// killed when ctx is done or the timeout passes.
+func RunOutputContext(ctx context.Context, b Backend, op string, timeout time.Duration) (string, error) {
 	cmd, ok := b.Cmds[op]
 	if !ok {
 		return "", fmt.Errorf("%s: unknown operation %q", b.Name, op)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	c := exec.CommandContext(ctx, cmd.Args[0], cmd.Args[1:]...)
+	c := NewCmdContext(timeoutCtx, cmd.Args[0], cmd.Args[1:]...)
 	output, err := c.CombinedOutput()
-	if ctx.Err() == context.DeadlineExceeded {
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s %s: %w", b.Name, op, ctx.Err())
+	}
+	if timeoutCtx.Err() == context.DeadlineExceeded {
 		return "", fmt.Errorf("%s %s timed out", b.Name, op)
 	}
 	if err != nil {
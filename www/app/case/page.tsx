@@ -1,63 +1,116 @@
 "use client"
 
-import React, { useState } from "react"
+import React, { useEffect, useState } from "react"
+
+// Conversions come from `yui serve --http`, so the page matches the CLI exactly.
+// Without an API URL the page has nothing to convert with and says so.
+const apiURL = process.env.NEXT_PUBLIC_YUI_API_URL
 
 interface CaseBoxProps {
-  input: string
   name: string
-  transformer: (str: string) => string
+  output: string | undefined
 }
 
-function CaseBox({ input, name, transformer }: CaseBoxProps) {
+function CaseBox({ name, output }: CaseBoxProps) {
   return (
     <li className="w-full rounded-md bg-slate-50 p-4 text-left shadow-lg">
       <label className="mb-1 block text-sm font-semibold text-gray-700">{name}</label>
 
-      <span className="inline-block w-full text-center text-lg">{transformer(input)}</span>
+      <span className="inline-block w-full text-center text-lg">{output}</span>
     </li>
   )
 }
 
-const casings: {
+interface Casing {
   name: string
-  transformer: (str: string) => string
-}[] = [
-  {
-    name: "Upper Case",
-    transformer: (str: string) => str.toUpperCase(),
-  },
-  {
-    name: "Lower Case",
-    transformer: (str) => str.toLowerCase(),
-  },
-  {
-    name: "Kebab Case",
-    transformer: (str) => str.split(" ").join("-"),
-  },
-  {
-    name: "Snake Case",
-    transformer: (str) => str.split(" ").join("_"),
-  },
-  {
-    name: "Camel Case",
-    transformer: (str) =>
-      str
-        .split(" ")
-        .map((w) => w.charAt(0).toLowerCase() + w.slice(1))
-        .join(""),
-  },
-  {
-    name: "Pascal Case",
-    transformer: (str) =>
-      str
-        .split(" ")
-        .map((w) => w.charAt(0).toUpperCase() + w.slice(1))
-        .join(""),
-  },
+  conversion: string
+  style?: string
+}
+
+const casings: Casing[] = [
+  { name: "Upper Case", conversion: "upper" },
+  { name: "Lower Case", conversion: "lower" },
+  { name: "Kebab Case", conversion: "kebab" },
+  { name: "Snake Case", conversion: "snake" },
+  { name: "Camel Case", conversion: "camel" },
+  { name: "Pascal Case", conversion: "pascal" },
 ]
 
+const titleStyles: Casing[] = [
+  { name: "APA", conversion: "title", style: "apa" },
+  { name: "Chicago", conversion: "title", style: "chicago" },
+  { name: "MLA", conversion: "title", style: "mla" },
+  { name: "AP", conversion: "title", style: "ap" },
+  { name: "Bluebook", conversion: "title", style: "bluebook" },
+  { name: "AMA", conversion: "title", style: "ama" },
+  { name: "NY Times", conversion: "title", style: "nytimes" },
+  { name: "Wikipedia", conversion: "title", style: "wikipedia" },
+]
+
+async function convert(
+  api: string,
+  text: string,
+  casing: Casing,
+  signal: AbortSignal
+): Promise<string> {
+  const res = await fetch(`${api}/case`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ conversion: casing.conversion, style: casing.style, text }),
+    signal,
+  })
+  const body = await res.json()
+  if (!res.ok) {
+    throw new Error(body.error)
+  }
+  return body.output
+}
+
 export default function Page() {
   const [input, setInput] = useState("")
+  const [outputs, setOutputs] = useState<Record<string, string>>({})
+  const [error, setError] = useState("")
+
+  useEffect(() => {
+    if (!apiURL) {
+      setError(
+        "Conversions are unavailable: set NEXT_PUBLIC_YUI_API_URL to a `yui serve --http` address."
+      )
+      return
+    }
+    if (input.trim() === "") {
+      setOutputs({})
+      setError("")
+      return
+    }
+
+    const controller = new AbortController()
+    const timer = setTimeout(async () => {
+      try {
+        const all = [...casings, ...titleStyles]
+        const results = await Promise.all(
+          all.map((casing) => convert(apiURL, input, casing, controller.signal))
+        )
+        setOutputs(Object.fromEntries(all.map((casing, i) => [casing.name, results[i]])))
+        setError("")
+      } catch (err) {
+        if (controller.signal.aborted) {
+          return
+        }
+        setOutputs({})
+        setError(
+          err instanceof TypeError
+            ? `Conversions are unavailable: could not reach ${apiURL}; start it with \`yui serve --http localhost:7777 --cors ${window.location.origin}\`.`
+            : String(err instanceof Error ? err.message : err)
+        )
+      }
+    }, 150)
+
+    return () => {
+      clearTimeout(timer)
+      controller.abort()
+    }
+  }, [input])
 
   return (
     <main className="flex min-h-screen flex-col items-center px-4 py-24">
@@ -70,17 +123,17 @@ export default function Page() {
           onChange={(e) => setInput(e.target.value)}
           className="mt-8 w-4/5 rounded-sm border bg-slate-50 p-2.5 text-lg"
         />
+        {error && <p className="mt-4 text-sm text-red-700">{error}</p>}
         <ul className="mt-10 flex flex-col gap-6">
-          {casings.map((casing) => {
-            return (
-              <CaseBox
-                key={casing.name}
-                input={input}
-                name={casing.name}
-                transformer={casing.transformer}
-              />
-            )
-          })}
+          {casings.map((casing) => (
+            <CaseBox key={casing.name} name={casing.name} output={outputs[casing.name]} />
+          ))}
+        </ul>
+        <h2 className="mt-12 text-2xl font-bold">Title Case</h2>
+        <ul className="mt-6 flex flex-col gap-6">
+          {titleStyles.map((casing) => (
+            <CaseBox key={casing.name} name={casing.name} output={outputs[casing.name]} />
+          ))}
         </ul>
       </div>
     </main>